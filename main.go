@@ -1,6 +1,14 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
 	"psm/psm"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -8,9 +16,80 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "export" {
+		if err := runExport(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	plugin.Serve(&plugin.ServeOpts{
 		ProviderFunc: func() *schema.Provider {
 			return psm.Provider()
 		},
 	})
 }
+
+// runExport implements the export subcommand, which renders existing PSM objects as Terraform HCL with
+// matching import blocks. Either a single object is exported with --kind and --name, or every supported kind
+// is written to --output-dir with --all. Credentials default to the same environment variables used by the provider.
+func runExport(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	kind := flags.String("kind", "", "PSM kind to export ("+strings.Join(psm.ExportKinds(), ", ")+")")
+	name := flags.String("name", "", "Name of the PSM object to export")
+	output := flags.String("output", "", "File to write the generated HCL to (defaults to stdout)")
+	all := flags.Bool("all", false, "Export every supported kind, one .tf file per kind")
+	outputDir := flags.String("output-dir", ".", "Directory to write the generated files to when using --all")
+	server := flags.String("server", os.Getenv("API_SERVER"), "The PSM server IP address or URL")
+	user := flags.String("user", os.Getenv("API_USER"), "The username for the PSM Server")
+	password := flags.String("password", os.Getenv("API_PASSWORD"), "The users password for the PSM Server")
+	insecure := flags.Bool("insecure", false, "Skip SSL certificate verification")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	// Provider debug logging is only useful when the user asks for it
+	if os.Getenv("TF_LOG") == "" {
+		log.SetOutput(io.Discard)
+	}
+
+	if *all && (*kind != "" || *name != "") {
+		return fmt.Errorf("--all cannot be combined with --kind or --name")
+	}
+	if !*all && (*kind == "" || *name == "") {
+		return fmt.Errorf("either --all or both --kind and --name are required")
+	}
+	if *server == "" || *user == "" || *password == "" {
+		return fmt.Errorf("--server, --user and --password (or API_SERVER, API_USER and API_PASSWORD) are required")
+	}
+
+	config := &psm.Config{
+		User:     *user,
+		Password: *password,
+		Server:   *server,
+		Insecure: *insecure,
+	}
+	if err := config.Authenticate(); err != nil {
+		return err
+	}
+
+	if *all {
+		if err := os.MkdirAll(*outputDir, 0755); err != nil {
+			return err
+		}
+		return psm.ExportAll(context.Background(), config, *outputDir)
+	}
+
+	out := os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	return psm.Export(context.Background(), config, *kind, *name, out)
+}
@@ -6,7 +6,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -22,6 +24,7 @@ func resourceApps() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: resourceAppsImport,
 		},
+		CustomizeDiff: resourceAppsCustomizeDiff,
 		Schema: map[string]*schema.Schema{
 			"kind": {
 				Type:     schema.TypeString,
@@ -115,8 +118,10 @@ func resourceApps() *schema.Resource {
 							Elem:     &schema.Schema{Type: schema.TypeString},
 						},
 						"timeout": {
-							Type:     schema.TypeString,
-							Optional: true,
+							Type:        schema.TypeString,
+							Optional:    true,
+							Computed:    true,
+							Description: "Idle timeout of the app, defaulted by PSM when not set",
 						},
 						"alg": {
 							Type:     schema.TypeList,
@@ -125,8 +130,10 @@ func resourceApps() *schema.Resource {
 							Elem: &schema.Resource{
 								Schema: map[string]*schema.Schema{
 									"type": {
-										Type:     schema.TypeString,
-										Required: true,
+										Type:         schema.TypeString,
+										Required:     true,
+										ValidateFunc: validation.StringInSlice(appALGTypes, false),
+										Description:  "ALG type, only the nested block of the same name may be set",
 									},
 									"icmp": {
 										Type:     schema.TypeList,
@@ -135,12 +142,14 @@ func resourceApps() *schema.Resource {
 										Elem: &schema.Resource{
 											Schema: map[string]*schema.Schema{
 												"type": {
-													Type:     schema.TypeString,
-													Required: true,
+													Type:         schema.TypeString,
+													Required:     true,
+													ValidateFunc: validateIntString(0, 255),
 												},
 												"code": {
-													Type:     schema.TypeString,
-													Required: true,
+													Type:         schema.TypeString,
+													Required:     true,
+													ValidateFunc: validateIntString(0, 255),
 												},
 											},
 										},
@@ -170,7 +179,7 @@ func resourceApps() *schema.Resource {
 													Type:         schema.TypeInt,
 													Optional:     true,
 													Default:      512,
-													ValidateFunc: validation.IntBetween(1, 8129),
+													ValidateFunc: validation.IntBetween(1, maxDNSMessageLength),
 												},
 											},
 										},
@@ -194,8 +203,10 @@ func resourceApps() *schema.Resource {
 										Elem: &schema.Resource{
 											Schema: map[string]*schema.Schema{
 												"program_id": {
-													Type:     schema.TypeString,
-													Required: true,
+													Type:         schema.TypeString,
+													Required:     true,
+													ValidateFunc: validateIntString(0, math.MaxUint32),
+													Description:  "ONC RPC program number, such as 100003 for NFS",
 												},
 											},
 										},
@@ -206,8 +217,13 @@ func resourceApps() *schema.Resource {
 										Elem: &schema.Resource{
 											Schema: map[string]*schema.Schema{
 												"program_uuid": {
-													Type:     schema.TypeString,
-													Required: true,
+													Type:         schema.TypeString,
+													Required:     true,
+													ValidateFunc: validation.IsUUID,
+													DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+														return strings.EqualFold(old, new)
+													},
+													Description: "DCE/RPC interface UUID",
 												},
 											},
 										},
@@ -234,6 +250,7 @@ func resourceApps() *schema.Resource {
 					},
 				},
 			},
+			"force_detach": forceDetachSchema(),
 		},
 	}
 }
@@ -344,50 +361,7 @@ func resourceAppsCreate(ctx context.Context, d *schema.ResourceData, m interface
 		}
 
 		if algList, ok := specMap["alg"].([]interface{}); ok && len(algList) > 0 {
-			algMap := algList[0].(map[string]interface{})
-			algType := algMap["type"].(string)
-
-			alg := &ALG{
-				Type: algType,
-			}
-
-			if icmp, ok := algMap["icmp"].([]interface{}); ok && len(icmp) > 0 {
-				alg.ICMP = parseICMP(icmp[0].(map[string]interface{}))
-			}
-			if dns, ok := algMap["dns"].([]interface{}); ok && len(dns) > 0 {
-				dnsMap := dns[0].(map[string]interface{})
-				alg.DNS = &DNS{}
-
-				if v, ok := dnsMap["drop_multi_question_packets"]; ok {
-					alg.DNS.DropMultiQuestionPackets = v.(bool)
-				}
-				if v, ok := dnsMap["drop_large_domain_name_packets"]; ok {
-					alg.DNS.DropLargeDomainNamePackets = v.(bool)
-				}
-				if v, ok := dnsMap["drop_long_label_packets"]; ok {
-					alg.DNS.DropLongLabelPackets = v.(bool)
-				}
-				if v, ok := dnsMap["max_message_length"]; ok {
-					alg.DNS.MaxMessageLength = int64(v.(int))
-				}
-			}
-			if ftp, ok := algMap["ftp"].([]interface{}); ok && len(ftp) > 0 {
-				alg.FTP = parseFTP(ftp[0].(map[string]interface{}))
-			}
-			if sunrpc, ok := algMap["sunrpc"].([]interface{}); ok {
-				alg.SunRPC = make([]SunRPC, len(sunrpc))
-				for i, s := range sunrpc {
-					sunrpcMap := s.(map[string]interface{})
-					alg.SunRPC[i] = SunRPC{
-						ProgramID: sunrpcMap["program_id"].(string),
-					}
-				}
-			}
-			if msrpc, ok := algMap["msrpc"].([]interface{}); ok {
-				alg.MSRPC = parseMSRPC(msrpc)
-			}
-
-			app.Spec.ALG = alg
+			app.Spec.ALG = expandALG(algList[0].(map[string]interface{}))
 		}
 	}
 
@@ -500,66 +474,8 @@ func resourceAppsRead(ctx context.Context, d *schema.ResourceData, m interface{}
 		spec["timeout"] = *app.Spec.Timeout
 	}
 
-	if app.Spec.ALG != nil {
-		algMap := map[string]interface{}{
-			"type": app.Spec.ALG.Type,
-		}
-		// Set ALG fields based on the type
-		switch app.Spec.ALG.Type {
-		case "icmp":
-			if app.Spec.ALG.ICMP != nil {
-				algMap["icmp"] = []interface{}{
-					map[string]interface{}{
-						"type": app.Spec.ALG.ICMP.Type,
-						"code": app.Spec.ALG.ICMP.Code,
-					},
-				}
-			}
-		case "dns":
-			if app.Spec.ALG.DNS != nil {
-				algMap["dns"] = []interface{}{
-					map[string]interface{}{
-						"drop_multi_question_packets":    app.Spec.ALG.DNS.DropMultiQuestionPackets,
-						"drop_large_domain_name_packets": app.Spec.ALG.DNS.DropLargeDomainNamePackets,
-						"drop_long_label_packets":        app.Spec.ALG.DNS.DropLongLabelPackets,
-						"max_message_length":             app.Spec.ALG.DNS.MaxMessageLength,
-					},
-				}
-			}
-		case "ftp":
-			if app.Spec.ALG.FTP != nil {
-				algMap["ftp"] = []interface{}{
-					map[string]interface{}{
-						"allow_mismatch_ip_address": app.Spec.ALG.FTP.AllowMismatchIPAddress,
-					},
-				}
-			}
-		case "sunrpc":
-			if len(app.Spec.ALG.SunRPC) > 0 {
-				sunrpc := make([]map[string]interface{}, len(app.Spec.ALG.SunRPC))
-				for i, s := range app.Spec.ALG.SunRPC {
-					sunrpc[i] = map[string]interface{}{
-						"program_id": s.ProgramID,
-					}
-				}
-				algMap["sunrpc"] = sunrpc
-			}
-		case "msrpc":
-			if len(app.Spec.ALG.MSRPC) > 0 {
-				msrpc := make([]map[string]interface{}, len(app.Spec.ALG.MSRPC))
-				for i, m := range app.Spec.ALG.MSRPC {
-					msrpc[i] = map[string]interface{}{
-						"program_uuid": m.ProgramUUID,
-					}
-				}
-				algMap["msrpc"] = msrpc
-			}
-		case "tftp":
-			algMap["tftp"] = []interface{}{map[string]interface{}{}}
-		case "rtsp":
-			algMap["rtsp"] = []interface{}{map[string]interface{}{}}
-		}
-		spec["alg"] = []interface{}{algMap}
+	if app.Spec.ALG != nil && app.Spec.ALG.Type != "" {
+		spec["alg"] = flattenALG(d, app.Spec.ALG)
 	}
 
 	if app.Spec.Timeout != nil {
@@ -634,42 +550,7 @@ func resourceAppsUpdate(ctx context.Context, d *schema.ResourceData, m interface
 			}
 
 			if algList, ok := specMap["alg"].([]interface{}); ok && len(algList) > 0 {
-				algMap := algList[0].(map[string]interface{})
-				algType := algMap["type"].(string)
-
-				alg := &ALG{
-					Type: algType,
-				}
-
-				// Handle ALG fields based on type
-				switch algType {
-				case "icmp":
-					if icmp, ok := algMap["icmp"].([]interface{}); ok && len(icmp) > 0 {
-						alg.ICMP = parseICMP(icmp[0].(map[string]interface{}))
-					}
-				case "dns":
-					if dns, ok := algMap["dns"].([]interface{}); ok && len(dns) > 0 {
-						alg.DNS = parseDNS(dns[0].(map[string]interface{}))
-					}
-				case "ftp":
-					if ftp, ok := algMap["ftp"].([]interface{}); ok && len(ftp) > 0 {
-						alg.FTP = parseFTP(ftp[0].(map[string]interface{}))
-					}
-				case "sunrpc":
-					if sunrpc, ok := algMap["sunrpc"].([]interface{}); ok {
-						alg.SunRPC = parseSunRPC(sunrpc)
-					}
-				case "msrpc":
-					if msrpc, ok := algMap["msrpc"].([]interface{}); ok {
-						alg.MSRPC = parseMSRPC(msrpc)
-					}
-				case "tftp":
-					alg.TFTP = &TFTP{}
-				case "rtsp":
-					alg.RTSP = &RTSP{}
-				}
-
-				app.Spec.ALG = alg
+				app.Spec.ALG = expandALG(algList[0].(map[string]interface{}))
 			}
 		}
 	}
@@ -705,6 +586,11 @@ func resourceAppsDelete(ctx context.Context, d *schema.ResourceData, m interface
 	config := m.(*Config)
 	client := config.Client()
 
+	// Rules reference the app by its name, which is also its UUID
+	if diags := checkPolicyReferences(ctx, d, config, referenceApp, d.Id()); diags.HasError() {
+		return diags
+	}
+
 	// Construct the URL for the app based on its UUID
 	url := config.Server + "/configs/security/v1/tenant/default/apps/" + d.Id()
 
@@ -744,7 +630,7 @@ func parseDNS(dnsMap map[string]interface{}) *DNS {
 		DropMultiQuestionPackets:   dnsMap["drop_multi_question_packets"].(bool),
 		DropLargeDomainNamePackets: dnsMap["drop_large_domain_name_packets"].(bool),
 		DropLongLabelPackets:       dnsMap["drop_long_label_packets"].(bool),
-		MaxMessageLength:           dnsMap["max_message_length"].(int64),
+		MaxMessageLength:           int64(dnsMap["max_message_length"].(int)),
 	}
 }
 
@@ -775,6 +661,7 @@ func parseMSRPC(msrpc []interface{}) []MSRPC {
 	}
 	return msrpcs
 }
+
 func resourceAppsImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
 	config := m.(*Config)
 	client := config.Client()
@@ -818,3 +705,173 @@ func resourceAppsImport(ctx context.Context, d *schema.ResourceData, m interface
 
 	return []*schema.ResourceData{d}, nil
 }
+
+// appALGTypes are the application layer gateways PSM supports, each configured by the nested alg block of the
+// same name.
+var appALGTypes = []string{"icmp", "dns", "ftp", "sunrpc", "msrpc", "tftp", "rtsp"}
+
+const (
+	maxDNSMessageLength     = 8192
+	defaultDNSMessageLength = 512
+)
+
+// expandALG builds the ALG of an app. Only the block matching the ALG type is sent, which CustomizeDiff ensures is
+// the only one set.
+func expandALG(algMap map[string]interface{}) *ALG {
+	alg := &ALG{
+		Type: algMap["type"].(string),
+	}
+
+	switch alg.Type {
+	case "icmp":
+		if icmp, ok := algMap["icmp"].([]interface{}); ok && len(icmp) > 0 {
+			alg.ICMP = parseICMP(icmp[0].(map[string]interface{}))
+		}
+	case "dns":
+		if dns, ok := algMap["dns"].([]interface{}); ok && len(dns) > 0 && dns[0] != nil {
+			alg.DNS = parseDNS(dns[0].(map[string]interface{}))
+		}
+	case "ftp":
+		if ftp, ok := algMap["ftp"].([]interface{}); ok && len(ftp) > 0 && ftp[0] != nil {
+			alg.FTP = parseFTP(ftp[0].(map[string]interface{}))
+		}
+	case "sunrpc":
+		if sunrpc, ok := algMap["sunrpc"].([]interface{}); ok {
+			alg.SunRPC = parseSunRPC(sunrpc)
+		}
+	case "msrpc":
+		if msrpc, ok := algMap["msrpc"].([]interface{}); ok {
+			alg.MSRPC = parseMSRPC(msrpc)
+		}
+	case "tftp":
+		alg.TFTP = &TFTP{}
+	case "rtsp":
+		alg.RTSP = &RTSP{}
+	}
+
+	return alg
+}
+
+// flattenALG returns the alg block of an app. The dns, ftp, tftp and rtsp blocks are optional and PSM fills in
+// defaults for them, so they are only set when configured or when PSM holds non-default values, to avoid diffs
+// against configurations that leave them out.
+func flattenALG(d *schema.ResourceData, alg *ALG) []interface{} {
+	configured := func(block string) bool {
+		v, ok := d.Get("spec.0.alg.0." + block).([]interface{})
+		return ok && len(v) > 0
+	}
+
+	algMap := map[string]interface{}{
+		"type": alg.Type,
+	}
+	switch alg.Type {
+	case "icmp":
+		if alg.ICMP != nil {
+			algMap["icmp"] = []interface{}{
+				map[string]interface{}{
+					"type": alg.ICMP.Type,
+					"code": alg.ICMP.Code,
+				},
+			}
+		}
+	case "dns":
+		if alg.DNS != nil {
+			dns := *alg.DNS
+			if dns.MaxMessageLength == 0 {
+				dns.MaxMessageLength = defaultDNSMessageLength
+			}
+			if configured("dns") || dns != (DNS{MaxMessageLength: defaultDNSMessageLength}) {
+				algMap["dns"] = []interface{}{
+					map[string]interface{}{
+						"drop_multi_question_packets":    dns.DropMultiQuestionPackets,
+						"drop_large_domain_name_packets": dns.DropLargeDomainNamePackets,
+						"drop_long_label_packets":        dns.DropLongLabelPackets,
+						"max_message_length":             dns.MaxMessageLength,
+					},
+				}
+			}
+		}
+	case "ftp":
+		if alg.FTP != nil && (configured("ftp") || alg.FTP.AllowMismatchIPAddress) {
+			algMap["ftp"] = []interface{}{
+				map[string]interface{}{
+					"allow_mismatch_ip_address": alg.FTP.AllowMismatchIPAddress,
+				},
+			}
+		}
+	case "sunrpc":
+		sunrpc := make([]interface{}, len(alg.SunRPC))
+		for i, s := range alg.SunRPC {
+			sunrpc[i] = map[string]interface{}{
+				"program_id": s.ProgramID,
+			}
+		}
+		algMap["sunrpc"] = sunrpc
+	case "msrpc":
+		msrpc := make([]interface{}, len(alg.MSRPC))
+		for i, m := range alg.MSRPC {
+			msrpc[i] = map[string]interface{}{
+				"program_uuid": m.ProgramUUID,
+			}
+		}
+		algMap["msrpc"] = msrpc
+	case "tftp", "rtsp":
+		if configured(alg.Type) {
+			algMap[alg.Type] = []interface{}{map[string]interface{}{}}
+		}
+	}
+	return []interface{}{algMap}
+}
+
+// resourceAppsCustomizeDiff checks at plan time that the ALG of an app only sets the nested block matching its
+// type, that the blocks the type requires are present, and that RPC programs are not listed twice.
+func resourceAppsCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !d.NewValueKnown("spec.0.alg.0.type") {
+		return nil
+	}
+	algList, _ := d.Get("spec.0.alg").([]interface{})
+	if len(algList) == 0 || algList[0] == nil {
+		return nil
+	}
+	algMap := algList[0].(map[string]interface{})
+	algType := algMap["type"].(string)
+
+	for _, block := range appALGTypes {
+		if v, _ := algMap[block].([]interface{}); block != algType && len(v) > 0 {
+			return fmt.Errorf("spec.0.alg.0.%s cannot be set when the ALG type is %q, set type = %q or remove the block", block, algType, block)
+		}
+	}
+
+	switch algType {
+	case "icmp":
+		if v, _ := algMap["icmp"].([]interface{}); len(v) == 0 {
+			return fmt.Errorf("spec.0.alg.0.icmp is required when the ALG type is \"icmp\"")
+		}
+	case "sunrpc":
+		return checkUniqueALGPrograms(d, algMap, "sunrpc", "program_id")
+	case "msrpc":
+		return checkUniqueALGPrograms(d, algMap, "msrpc", "program_uuid")
+	}
+	return nil
+}
+
+func checkUniqueALGPrograms(d *schema.ResourceDiff, algMap map[string]interface{}, block, key string) error {
+	programs, _ := algMap[block].([]interface{})
+	if len(programs) == 0 {
+		return fmt.Errorf("spec.0.alg.0.%s requires at least one program when the ALG type is %q", block, block)
+	}
+
+	seen := make(map[string]int)
+	for i, program := range programs {
+		programMap, ok := program.(map[string]interface{})
+		if !ok || !d.NewValueKnown(fmt.Sprintf("spec.0.alg.0.%s.%d.%s", block, i, key)) {
+			continue
+		}
+		id := strings.ToLower(programMap[key].(string))
+		if j, ok := seen[id]; ok {
+			return fmt.Errorf("spec.0.alg.0.%s.%d.%s: %s is already listed in spec.0.alg.0.%s.%d", block, i, key, id, block, j)
+		}
+		seen[id] = i
+	}
+	return nil
+}
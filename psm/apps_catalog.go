@@ -0,0 +1,302 @@
+package psm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func dataSourceAppsCatalog() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceAppsCatalogRead,
+		Schema: map[string]*schema.Schema{
+			"category": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "all",
+				ValidateFunc: validation.StringInSlice([]string{"all", "fixed_name", "uuid_named"}, false),
+				Description:  "Which apps to return: all, fixed_name (usually predefined by PSM) or uuid_named (usually custom)",
+			},
+			"alg_type": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice(appALGTypes, false),
+				Description:  "Only return apps with this ALG type",
+			},
+			"names": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Sorted names of the returned apps, as referenced by rules",
+			},
+			"apps": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: appDataSourceSchema(),
+				},
+			},
+		},
+	}
+}
+
+// dataSourceApp looks up a single app, so that rules can reference built-in apps by a name that is known to exist.
+func dataSourceApp() *schema.Resource {
+	s := appDataSourceSchema()
+	s["name"] = &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		Computed:     true,
+		ExactlyOneOf: []string{"name", "display_name"},
+		Description:  "Name of the app, such as SSH",
+	}
+	s["display_name"] = &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		Computed:     true,
+		ExactlyOneOf: []string{"name", "display_name"},
+		Description:  "Display name of the app, to look up custom apps whose name PSM generated",
+	}
+
+	return &schema.Resource{
+		ReadContext: dataSourceAppRead,
+		Schema:      s,
+	}
+}
+
+// appDataSourceSchema describes an app as returned by psm_app and psm_apps_catalog.
+func appDataSourceSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"name": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"display_name": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"uuid": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"uuid_named": {
+			Type:        schema.TypeBool,
+			Computed:    true,
+			Description: "True for apps named after their UUID, as custom apps created with psm_app or the PSM UI are",
+		},
+		"proto_ports": {
+			Type:     schema.TypeList,
+			Computed: true,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"protocol": {
+						Type:     schema.TypeString,
+						Computed: true,
+					},
+					"ports": {
+						Type:     schema.TypeString,
+						Computed: true,
+					},
+				},
+			},
+		},
+		"apps": {
+			Type:     schema.TypeList,
+			Computed: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+		"alg_type": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"timeout": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+	}
+}
+
+func dataSourceAppsCatalogRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	config := m.(*Config)
+	category := d.Get("category").(string)
+	algType := d.Get("alg_type").(string)
+
+	apps, err := listApps(ctx, config)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	sort.Slice(apps, func(i, j int) bool { return apps[i].Meta.Name < apps[j].Meta.Name })
+
+	names := []string{}
+	result := make([]interface{}, 0, len(apps))
+	for i := range apps {
+		app := &apps[i]
+		switch {
+		case category == "fixed_name" && isUUIDNamedApp(app):
+			continue
+		case category == "uuid_named" && !isUUIDNamedApp(app):
+			continue
+		case algType != "" && (app.Spec.ALG == nil || app.Spec.ALG.Type != algType):
+			continue
+		}
+		names = append(names, app.Meta.Name)
+		result = append(result, flattenAppDataSource(app))
+	}
+
+	if err := d.Set("names", names); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("apps", result); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(fmt.Sprintf("%s/%s", category, algType))
+
+	return nil
+}
+
+func dataSourceAppRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	config := m.(*Config)
+
+	var app *App
+	if name := d.Get("name").(string); name != "" {
+		var err error
+		if app, err = getApp(ctx, config, name); err != nil {
+			return diag.FromErr(err)
+		}
+		if app == nil {
+			return diag.Errorf("app %s not found, the psm_apps_catalog data source lists the available apps", name)
+		}
+	} else {
+		displayName := d.Get("display_name").(string)
+		apps, err := listApps(ctx, config)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		var matches []string
+		for i := range apps {
+			if apps[i].Meta.DisplayName == displayName {
+				app = &apps[i]
+				matches = append(matches, apps[i].Meta.Name)
+			}
+		}
+		switch {
+		case len(matches) == 0:
+			return diag.Errorf("no app with display name %s found", displayName)
+		case len(matches) > 1:
+			return diag.Errorf("%d apps have the display name %s, look one up by name instead: %v", len(matches), displayName, matches)
+		}
+	}
+
+	for k, v := range flattenAppDataSource(app) {
+		if err := d.Set(k, v); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	d.SetId(app.Meta.Name)
+
+	return nil
+}
+
+func flattenAppDataSource(app *App) map[string]interface{} {
+	protoPorts := make([]interface{}, len(app.Spec.ProtoPorts))
+	for i, pp := range app.Spec.ProtoPorts {
+		protoPorts[i] = map[string]interface{}{
+			"protocol": pp.Protocol,
+			"ports":    pp.Ports,
+		}
+	}
+
+	algType, timeout := "", ""
+	if app.Spec.ALG != nil {
+		algType = app.Spec.ALG.Type
+	}
+	if app.Spec.Timeout != nil {
+		timeout = *app.Spec.Timeout
+	}
+	uuid, _ := app.Meta.UUID.(string)
+
+	return map[string]interface{}{
+		"name":         app.Meta.Name,
+		"display_name": app.Meta.DisplayName,
+		"uuid":         uuid,
+		"uuid_named":   isUUIDNamedApp(app),
+		"proto_ports":  protoPorts,
+		"apps":         app.Spec.Apps,
+		"alg_type":     algType,
+		"timeout":      timeout,
+	}
+}
+
+// isUUIDNamedApp reports whether an app is named after its UUID. PSM does not mark the apps it predefines, but
+// apps created through psm_app or the PSM UI are named after their UUID while predefined apps have fixed names
+// such as SSH or HTTPS, so this tells most custom apps apart. An app created through the API with a chosen name
+// has a fixed name as well.
+func isUUIDNamedApp(app *App) bool {
+	_, errs := validation.IsUUID(app.Meta.Name, "name")
+	return len(errs) == 0
+}
+
+func listApps(ctx context.Context, config *Config) ([]App, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", config.Server+"/configs/security/v1/tenant/default/apps", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.AddCookie(&http.Cookie{Name: "sid", Value: config.SID})
+
+	resp, err := config.Client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to list apps: HTTP %d %s: %s", resp.StatusCode, resp.Status, bodyBytes)
+	}
+
+	var list struct {
+		Items []App `json:"items"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// getApp returns the app with the given name, or nil if it does not exist.
+func getApp(ctx context.Context, config *Config, name string) (*App, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", config.Server+"/configs/security/v1/tenant/default/apps/"+name, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.AddCookie(&http.Cookie{Name: "sid", Value: config.SID})
+
+	resp, err := config.Client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to read app %s: HTTP %d %s: %s", name, resp.StatusCode, resp.Status, bodyBytes)
+	}
+
+	app := &App{}
+	if err := json.NewDecoder(resp.Body).Decode(app); err != nil {
+		return nil, err
+	}
+	return app, nil
+}
@@ -0,0 +1,148 @@
+package psm
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestIsUUIDNamedApp(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"SSH", false},
+		{"HTTPS", false},
+		{"my-app", false},
+		{"3f2a9c1e-8b7d-4e6f-a5c4-1d2e3f4a5b6c", true},
+		{"3F2A9C1E-8B7D-4E6F-A5C4-1D2E3F4A5B6C", true},
+		{"3f2a9c1e8b7d4e6fa5c41d2e3f4a5b6c", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		app := &App{}
+		app.Meta.Name = tt.name
+		if got := isUUIDNamedApp(app); got != tt.want {
+			t.Errorf("isUUIDNamedApp(%q) = %t, want %t", tt.name, got, tt.want)
+		}
+	}
+}
+
+func appsCatalogServer(t *testing.T) *Config {
+	return newTestServer(t, map[string]string{
+		"/configs/security/v1/tenant/default/apps": `{"items": [
+			{"meta": {"name": "SSH", "uuid": "u-ssh"}, "spec": {"proto-ports": [{"protocol": "tcp", "ports": "22"}], "timeout": "30m"}},
+			{"meta": {"name": "DNS", "uuid": "u-dns"}, "spec": {"proto-ports": [{"protocol": "udp", "ports": "53"}], "alg": {"type": "dns"}}},
+			{"meta": {"name": "3f2a9c1e-8b7d-4e6f-a5c4-1d2e3f4a5b6c", "display-name": "internal-dns", "uuid": "3f2a9c1e-8b7d-4e6f-a5c4-1d2e3f4a5b6c"}, "spec": {"alg": {"type": "dns"}}},
+			{"meta": {"name": "7b1d4f2a-0c3e-4a5b-9d8c-6e7f8a9b0c1d", "display-name": "web", "uuid": "7b1d4f2a-0c3e-4a5b-9d8c-6e7f8a9b0c1d"}, "spec": {"apps": ["HTTP", "HTTPS"]}},
+			{"meta": {"name": "9c8b7a6d-5e4f-4a3b-8c2d-1e0f9a8b7c6d", "display-name": "web", "uuid": "9c8b7a6d-5e4f-4a3b-8c2d-1e0f9a8b7c6d"}, "spec": {}}
+		]}`,
+		"/configs/security/v1/tenant/default/apps/SSH": `{"meta": {"name": "SSH", "uuid": "u-ssh"}, "spec": {"proto-ports": [{"protocol": "tcp", "ports": "22"}], "timeout": "30m"}}`,
+	})
+}
+
+func TestDataSourceAppsCatalogRead(t *testing.T) {
+	config := appsCatalogServer(t)
+
+	tests := []struct {
+		name    string
+		filters map[string]interface{}
+		want    []string
+	}{
+		{"all", map[string]interface{}{}, []string{"3f2a9c1e-8b7d-4e6f-a5c4-1d2e3f4a5b6c", "7b1d4f2a-0c3e-4a5b-9d8c-6e7f8a9b0c1d", "9c8b7a6d-5e4f-4a3b-8c2d-1e0f9a8b7c6d", "DNS", "SSH"}},
+		{"fixed name", map[string]interface{}{"category": "fixed_name"}, []string{"DNS", "SSH"}},
+		{"uuid named", map[string]interface{}{"category": "uuid_named"}, []string{"3f2a9c1e-8b7d-4e6f-a5c4-1d2e3f4a5b6c", "7b1d4f2a-0c3e-4a5b-9d8c-6e7f8a9b0c1d", "9c8b7a6d-5e4f-4a3b-8c2d-1e0f9a8b7c6d"}},
+		{"by ALG", map[string]interface{}{"alg_type": "dns"}, []string{"3f2a9c1e-8b7d-4e6f-a5c4-1d2e3f4a5b6c", "DNS"}},
+		{"fixed name by ALG", map[string]interface{}{"category": "fixed_name", "alg_type": "dns"}, []string{"DNS"}},
+		{"no match", map[string]interface{}{"alg_type": "ftp"}, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, dataSourceAppsCatalog().Schema, tt.filters)
+			if diags := dataSourceAppsCatalogRead(context.Background(), d, config); diags.HasError() {
+				t.Fatalf("read error = %v", diags)
+			}
+
+			if got := expandStringList(d.Get("names").([]interface{})); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("names = %q, want %q", got, tt.want)
+			}
+			if got := d.Get("apps.#").(int); got != len(tt.want) {
+				t.Errorf("apps = %d, want %d", got, len(tt.want))
+			}
+			for i, v := range d.Get("apps").([]interface{}) {
+				app := v.(map[string]interface{})
+				fixedName := app["name"] == "DNS" || app["name"] == "SSH"
+				if got := app["uuid_named"].(bool); got == fixedName {
+					t.Errorf("apps.%d.uuid_named = %t for %s", i, got, app["name"])
+				}
+			}
+		})
+	}
+}
+
+func TestDataSourceAppRead(t *testing.T) {
+	config := appsCatalogServer(t)
+
+	tests := []struct {
+		name     string
+		lookup   map[string]interface{}
+		wantName string
+		wantErr  string
+	}{
+		{"by name", map[string]interface{}{"name": "SSH"}, "SSH", ""},
+		{"unknown name", map[string]interface{}{"name": "TELNET"}, "", "app TELNET not found"},
+		{"by display name", map[string]interface{}{"display_name": "internal-dns"}, "3f2a9c1e-8b7d-4e6f-a5c4-1d2e3f4a5b6c", ""},
+		{"unknown display name", map[string]interface{}{"display_name": "ldap"}, "", "no app with display name ldap found"},
+		{"ambiguous display name", map[string]interface{}{"display_name": "web"}, "", "2 apps have the display name web"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, dataSourceApp().Schema, tt.lookup)
+			diags := dataSourceAppRead(context.Background(), d, config)
+			if tt.wantErr != "" {
+				if !diags.HasError() || !strings.Contains(diags[0].Summary, tt.wantErr) {
+					t.Errorf("read error = %v, want %q", diags, tt.wantErr)
+				}
+				return
+			}
+			if diags.HasError() {
+				t.Fatalf("read error = %v", diags)
+			}
+			if d.Id() != tt.wantName || d.Get("name").(string) != tt.wantName {
+				t.Errorf("id = %q, name = %q, want %q", d.Id(), d.Get("name"), tt.wantName)
+			}
+		})
+	}
+}
+
+func TestFlattenAppDataSource(t *testing.T) {
+	timeout := "30m"
+	app := &App{}
+	app.Meta.Name = "SSH"
+	app.Meta.UUID = "u-ssh"
+	app.Spec = AppSpec{
+		ProtoPorts: []ProtoPorts{{Protocol: "tcp", Ports: "22"}},
+		Timeout:    &timeout,
+		ALG:        &ALG{Type: "ftp"},
+	}
+
+	want := map[string]interface{}{
+		"name":         "SSH",
+		"display_name": "",
+		"uuid":         "u-ssh",
+		"uuid_named":   false,
+		"proto_ports":  []interface{}{map[string]interface{}{"protocol": "tcp", "ports": "22"}},
+		"apps":         []string(nil),
+		"alg_type":     "ftp",
+		"timeout":      "30m",
+	}
+	if got := flattenAppDataSource(app); !reflect.DeepEqual(got, want) {
+		t.Errorf("flattenAppDataSource() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,290 @@
+package psm
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestExpandALG(t *testing.T) {
+	dns := map[string]interface{}{
+		"drop_multi_question_packets":    true,
+		"drop_large_domain_name_packets": false,
+		"drop_long_label_packets":        true,
+		"max_message_length":             1024,
+	}
+
+	tests := []struct {
+		name string
+		alg  map[string]interface{}
+		want *ALG
+	}{
+		{
+			name: "icmp",
+			alg:  map[string]interface{}{"type": "icmp", "icmp": []interface{}{map[string]interface{}{"type": "8", "code": "0"}}},
+			want: &ALG{Type: "icmp", ICMP: &ICMP{Type: "8", Code: "0"}},
+		},
+		{
+			name: "dns",
+			alg:  map[string]interface{}{"type": "dns", "dns": []interface{}{dns}},
+			want: &ALG{Type: "dns", DNS: &DNS{DropMultiQuestionPackets: true, DropLongLabelPackets: true, MaxMessageLength: 1024}},
+		},
+		{
+			name: "dns without block",
+			alg:  map[string]interface{}{"type": "dns", "dns": []interface{}{}},
+			want: &ALG{Type: "dns"},
+		},
+		{
+			name: "ftp with empty block",
+			alg:  map[string]interface{}{"type": "ftp", "ftp": []interface{}{nil}},
+			want: &ALG{Type: "ftp"},
+		},
+		{
+			name: "ftp",
+			alg:  map[string]interface{}{"type": "ftp", "ftp": []interface{}{map[string]interface{}{"allow_mismatch_ip_address": true}}},
+			want: &ALG{Type: "ftp", FTP: &FTP{AllowMismatchIPAddress: true}},
+		},
+		{
+			name: "sunrpc",
+			alg: map[string]interface{}{"type": "sunrpc", "sunrpc": []interface{}{
+				map[string]interface{}{"program_id": "100003"},
+				map[string]interface{}{"program_id": "100005"},
+			}},
+			want: &ALG{Type: "sunrpc", SunRPC: []SunRPC{{ProgramID: "100003"}, {ProgramID: "100005"}}},
+		},
+		{
+			name: "msrpc",
+			alg: map[string]interface{}{"type": "msrpc", "msrpc": []interface{}{
+				map[string]interface{}{"program_uuid": "e1af8308-5d1f-11c9-91a4-08002b14a0fa"},
+			}},
+			want: &ALG{Type: "msrpc", MSRPC: []MSRPC{{ProgramUUID: "e1af8308-5d1f-11c9-91a4-08002b14a0fa"}}},
+		},
+		{
+			name: "tftp",
+			alg:  map[string]interface{}{"type": "tftp"},
+			want: &ALG{Type: "tftp", TFTP: &TFTP{}},
+		},
+		{
+			name: "rtsp",
+			alg:  map[string]interface{}{"type": "rtsp", "rtsp": []interface{}{nil}},
+			want: &ALG{Type: "rtsp", RTSP: &RTSP{}},
+		},
+		{
+			name: "blocks of other types are not sent",
+			alg: map[string]interface{}{
+				"type": "ftp",
+				"ftp":  []interface{}{map[string]interface{}{"allow_mismatch_ip_address": false}},
+				"dns":  []interface{}{dns},
+			},
+			want: &ALG{Type: "ftp", FTP: &FTP{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := expandALG(tt.alg); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expandALG() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlattenALG(t *testing.T) {
+	tests := []struct {
+		name       string
+		configured map[string]interface{}
+		alg        *ALG
+		want       map[string]interface{}
+	}{
+		{
+			name: "icmp",
+			alg:  &ALG{Type: "icmp", ICMP: &ICMP{Type: "8", Code: "0"}},
+			want: map[string]interface{}{"type": "icmp", "icmp": []interface{}{map[string]interface{}{"type": "8", "code": "0"}}},
+		},
+		{
+			name: "dns defaults not configured",
+			alg:  &ALG{Type: "dns", DNS: &DNS{}},
+			want: map[string]interface{}{"type": "dns"},
+		},
+		{
+			name:       "dns defaults configured",
+			configured: map[string]interface{}{"type": "dns", "dns": []interface{}{map[string]interface{}{}}},
+			alg:        &ALG{Type: "dns", DNS: &DNS{MaxMessageLength: 512}},
+			want: map[string]interface{}{"type": "dns", "dns": []interface{}{map[string]interface{}{
+				"drop_multi_question_packets":    false,
+				"drop_large_domain_name_packets": false,
+				"drop_long_label_packets":        false,
+				"max_message_length":             int64(512),
+			}}},
+		},
+		{
+			name: "dns changed outside of terraform",
+			alg:  &ALG{Type: "dns", DNS: &DNS{DropLongLabelPackets: true}},
+			want: map[string]interface{}{"type": "dns", "dns": []interface{}{map[string]interface{}{
+				"drop_multi_question_packets":    false,
+				"drop_large_domain_name_packets": false,
+				"drop_long_label_packets":        true,
+				"max_message_length":             int64(512),
+			}}},
+		},
+		{
+			name: "ftp default not configured",
+			alg:  &ALG{Type: "ftp", FTP: &FTP{}},
+			want: map[string]interface{}{"type": "ftp"},
+		},
+		{
+			name: "ftp allows mismatched addresses",
+			alg:  &ALG{Type: "ftp", FTP: &FTP{AllowMismatchIPAddress: true}},
+			want: map[string]interface{}{"type": "ftp", "ftp": []interface{}{map[string]interface{}{"allow_mismatch_ip_address": true}}},
+		},
+		{
+			name: "sunrpc",
+			alg:  &ALG{Type: "sunrpc", SunRPC: []SunRPC{{ProgramID: "100003"}}},
+			want: map[string]interface{}{"type": "sunrpc", "sunrpc": []interface{}{map[string]interface{}{"program_id": "100003"}}},
+		},
+		{
+			name: "msrpc",
+			alg:  &ALG{Type: "msrpc", MSRPC: []MSRPC{{ProgramUUID: "E1AF8308-5D1F-11C9-91A4-08002B14A0FA"}}},
+			want: map[string]interface{}{"type": "msrpc", "msrpc": []interface{}{map[string]interface{}{"program_uuid": "E1AF8308-5D1F-11C9-91A4-08002B14A0FA"}}},
+		},
+		{
+			name: "tftp not configured",
+			alg:  &ALG{Type: "tftp", TFTP: &TFTP{}},
+			want: map[string]interface{}{"type": "tftp"},
+		},
+		{
+			name:       "rtsp configured",
+			configured: map[string]interface{}{"type": "rtsp", "rtsp": []interface{}{map[string]interface{}{}}},
+			alg:        &ALG{Type: "rtsp", RTSP: &RTSP{}},
+			want:       map[string]interface{}{"type": "rtsp", "rtsp": []interface{}{map[string]interface{}{}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := map[string]interface{}{}
+			if tt.configured != nil {
+				spec["alg"] = []interface{}{tt.configured}
+			}
+			d := schema.TestResourceDataRaw(t, resourceApps().Schema, map[string]interface{}{
+				"display_name": "app",
+				"spec":         []interface{}{spec},
+			})
+
+			got := flattenALG(d, tt.alg)
+			if !reflect.DeepEqual(got, []interface{}{tt.want}) {
+				t.Errorf("flattenALG() = %v, want %v", got, []interface{}{tt.want})
+			}
+		})
+	}
+}
+
+func TestResourceAppsCustomizeDiff(t *testing.T) {
+	program := func(key, value string) map[string]interface{} {
+		return map[string]interface{}{key: value}
+	}
+
+	tests := []struct {
+		name    string
+		alg     map[string]interface{}
+		wantErr string
+	}{
+		{
+			name: "icmp",
+			alg:  map[string]interface{}{"type": "icmp", "icmp": []interface{}{map[string]interface{}{"type": "8", "code": "0"}}},
+		},
+		{
+			name:    "icmp without block",
+			alg:     map[string]interface{}{"type": "icmp"},
+			wantErr: `spec.0.alg.0.icmp is required when the ALG type is "icmp"`,
+		},
+		{
+			name: "dns without block",
+			alg:  map[string]interface{}{"type": "dns"},
+		},
+		{
+			name: "block of another type",
+			alg: map[string]interface{}{
+				"type": "dns",
+				"ftp":  []interface{}{map[string]interface{}{"allow_mismatch_ip_address": true}},
+			},
+			wantErr: `spec.0.alg.0.ftp cannot be set when the ALG type is "dns"`,
+		},
+		{
+			name:    "sunrpc without programs",
+			alg:     map[string]interface{}{"type": "sunrpc"},
+			wantErr: `spec.0.alg.0.sunrpc requires at least one program`,
+		},
+		{
+			name: "sunrpc",
+			alg: map[string]interface{}{"type": "sunrpc", "sunrpc": []interface{}{
+				program("program_id", "100003"),
+				program("program_id", "100005"),
+			}},
+		},
+		{
+			name: "sunrpc program listed twice",
+			alg: map[string]interface{}{"type": "sunrpc", "sunrpc": []interface{}{
+				program("program_id", "100003"),
+				program("program_id", "100003"),
+			}},
+			wantErr: "spec.0.alg.0.sunrpc.1.program_id",
+		},
+		{
+			name: "msrpc program listed twice in another case",
+			alg: map[string]interface{}{"type": "msrpc", "msrpc": []interface{}{
+				program("program_uuid", "e1af8308-5d1f-11c9-91a4-08002b14a0fa"),
+				program("program_uuid", "E1AF8308-5D1F-11C9-91A4-08002B14A0FA"),
+			}},
+			wantErr: "spec.0.alg.0.msrpc.1.program_uuid",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := testPlan(t, resourceApps(), nil, map[string]interface{}{
+				"display_name": "app",
+				"spec":         []interface{}{map[string]interface{}{"alg": []interface{}{tt.alg}}},
+			}, nil)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("plan error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("plan error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestResourceAppsValidateALG(t *testing.T) {
+	tests := []struct {
+		name    string
+		alg     map[string]interface{}
+		wantErr bool
+	}{
+		{"valid icmp", map[string]interface{}{"type": "icmp", "icmp": []interface{}{map[string]interface{}{"type": "8", "code": "0"}}}, false},
+		{"unknown type", map[string]interface{}{"type": "sip"}, true},
+		{"icmp type out of range", map[string]interface{}{"type": "icmp", "icmp": []interface{}{map[string]interface{}{"type": "256", "code": "0"}}}, true},
+		{"dns message too long", map[string]interface{}{"type": "dns", "dns": []interface{}{map[string]interface{}{"max_message_length": maxDNSMessageLength + 1}}}, true},
+		{"sunrpc program not a number", map[string]interface{}{"type": "sunrpc", "sunrpc": []interface{}{map[string]interface{}{"program_id": "nfs"}}}, true},
+		{"msrpc program not a UUID", map[string]interface{}{"type": "msrpc", "msrpc": []interface{}{map[string]interface{}{"program_uuid": "epmapper"}}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := resourceApps().Validate(terraform.NewResourceConfigRaw(map[string]interface{}{
+				"display_name": "app",
+				"spec":         []interface{}{map[string]interface{}{"alg": []interface{}{tt.alg}}},
+			}))
+			if diags.HasError() != tt.wantErr {
+				t.Errorf("Validate() = %v, want error %t", diags, tt.wantErr)
+			}
+		})
+	}
+}
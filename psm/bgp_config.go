@@ -0,0 +1,426 @@
+package psm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func resourceBGPConfig() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceBGPConfigCreate,
+		ReadContext:   resourceBGPConfigRead,
+		UpdateContext: resourceBGPConfigUpdate,
+		DeleteContext: resourceBGPConfigDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceBGPConfigImport,
+		},
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Name of the routing configuration",
+			},
+			"router_id": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.IsIPv4Address,
+			},
+			"as_number": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validateASN,
+				Description:  "Local autonomous system number, in plain (65001) or dotted (1.10) notation",
+			},
+			"keepalive_interval": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      60,
+				ValidateFunc: validation.IntBetween(0, 3600),
+			},
+			"holdtime": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      180,
+				ValidateFunc: validateBGPHoldtime,
+			},
+			"neighbor": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"ip_address": {
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.IsIPAddress,
+						},
+						"remote_as": {
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validateASN,
+						},
+						"password": {
+							Type:      schema.TypeString,
+							Optional:  true,
+							Sensitive: true,
+						},
+						"keepalive_interval": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      60,
+							ValidateFunc: validation.IntBetween(0, 3600),
+						},
+						"holdtime": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      180,
+							ValidateFunc: validateBGPHoldtime,
+						},
+						"multi_hop": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      1,
+							ValidateFunc: validation.IntBetween(1, 255),
+						},
+						"address_families": {
+							Type:     schema.TypeList,
+							Required: true,
+							MinItems: 1,
+							Elem: &schema.Schema{
+								Type:         schema.TypeString,
+								ValidateFunc: validation.StringInSlice([]string{"ipv4-unicast", "l2vpn-evpn"}, false),
+							},
+						},
+						"shutdown": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+type RoutingConfig struct {
+	Kind       interface{} `json:"kind"`
+	APIVersion interface{} `json:"api-version"`
+	Meta       struct {
+		Name            string      `json:"name"`
+		Tenant          string      `json:"tenant"`
+		Namespace       interface{} `json:"namespace"`
+		GenerationID    interface{} `json:"generation-id"`
+		ResourceVersion interface{} `json:"resource-version"`
+		UUID            string      `json:"uuid"`
+		Labels          interface{} `json:"labels"`
+		SelfLink        interface{} `json:"self-link"`
+	} `json:"meta"`
+	Spec struct {
+		BGPConfig *BGPConfig `json:"bgp-config"`
+	} `json:"spec"`
+}
+
+type BGPConfig struct {
+	RouterID          string        `json:"router-id"`
+	ASNumber          ASNumber      `json:"as-number"`
+	KeepaliveInterval int           `json:"keepalive-interval"`
+	Holdtime          int           `json:"holdtime"`
+	Neighbors         []BGPNeighbor `json:"neighbors"`
+}
+
+type BGPNeighbor struct {
+	Shutdown              bool     `json:"shutdown"`
+	IPAddress             string   `json:"ip-address"`
+	RemoteAS              ASNumber `json:"remote-as"`
+	MultiHop              int      `json:"multi-hop"`
+	EnableAddressFamilies []string `json:"enable-address-families"`
+	Password              string   `json:"password,omitempty"`
+	KeepaliveInterval     int      `json:"keepalive-interval"`
+	Holdtime              int      `json:"holdtime"`
+}
+
+type ASNumber struct {
+	ASDotNotation string `json:"as-dot-notation"`
+}
+
+func resourceBGPConfigCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	config := m.(*Config)
+	client := config.Client()
+
+	routingConfig := expandRoutingConfig(d)
+
+	jsonBytes, err := json.Marshal(routingConfig)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	log.Printf("[DEBUG] Creating BGP config with name: %s", routingConfig.Meta.Name)
+
+	req, err := http.NewRequestWithContext(ctx, "POST", config.Server+"/configs/network/v1/routingconfigs", bytes.NewBuffer(jsonBytes))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.AddCookie(&http.Cookie{Name: "sid", Value: config.SID})
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return diag.Errorf("failed to create BGP config: HTTP %d %s: %s", resp.StatusCode, resp.Status, bodyBytes)
+	}
+
+	responseBody := &RoutingConfig{}
+	if err := json.NewDecoder(resp.Body).Decode(responseBody); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(responseBody.Meta.UUID)
+
+	return resourceBGPConfigRead(ctx, d, m)
+}
+
+func resourceBGPConfigRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	config := m.(*Config)
+	client := config.Client()
+
+	url := config.Server + "/configs/network/v1/routingconfigs/" + d.Get("name").(string)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	req.AddCookie(&http.Cookie{Name: "sid", Value: config.SID})
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		d.SetId("")
+		return nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return diag.Errorf("failed to read BGP config: HTTP %d %s: %s", resp.StatusCode, resp.Status, bodyBytes)
+	}
+
+	routingConfig := &RoutingConfig{}
+	if err := json.NewDecoder(resp.Body).Decode(routingConfig); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.Set("name", routingConfig.Meta.Name)
+
+	bgp := routingConfig.Spec.BGPConfig
+	if bgp == nil {
+		bgp = &BGPConfig{}
+	}
+	d.Set("router_id", bgp.RouterID)
+	d.Set("as_number", bgp.ASNumber.ASDotNotation)
+	d.Set("keepalive_interval", bgp.KeepaliveInterval)
+	d.Set("holdtime", bgp.Holdtime)
+
+	// PSM does not return neighbor passwords, keep the configured ones
+	passwords := make(map[string]string)
+	for _, v := range d.Get("neighbor").([]interface{}) {
+		neighbor := v.(map[string]interface{})
+		passwords[neighbor["ip_address"].(string)] = neighbor["password"].(string)
+	}
+	if err := d.Set("neighbor", flattenBGPNeighbors(bgp.Neighbors, passwords)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+func resourceBGPConfigUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	config := m.(*Config)
+	client := config.Client()
+
+	routingConfig := expandRoutingConfig(d)
+
+	jsonBytes, err := json.Marshal(routingConfig)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	log.Printf("[DEBUG] Updating BGP config with name: %s", routingConfig.Meta.Name)
+
+	req, err := http.NewRequestWithContext(ctx, "PUT", config.Server+"/configs/network/v1/routingconfigs/"+routingConfig.Meta.Name, bytes.NewBuffer(jsonBytes))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.AddCookie(&http.Cookie{Name: "sid", Value: config.SID})
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return diag.Errorf("failed to update BGP config: HTTP %d %s: %s", resp.StatusCode, resp.Status, bodyBytes)
+	}
+
+	return resourceBGPConfigRead(ctx, d, m)
+}
+
+func resourceBGPConfigDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	config := m.(*Config)
+	client := config.Client()
+
+	url := config.Server + "/configs/network/v1/routingconfigs/" + d.Get("name").(string)
+
+	req, err := http.NewRequestWithContext(ctx, "DELETE", url, nil)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	req.AddCookie(&http.Cookie{Name: "sid", Value: config.SID})
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return diag.Errorf("failed to delete BGP config: HTTP %d %s: %s", resp.StatusCode, resp.Status, bodyBytes)
+	}
+
+	d.SetId("")
+
+	return nil
+}
+
+func resourceBGPConfigImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	config := m.(*Config)
+	client := config.Client()
+
+	// The import ID is the routing config name, the resource ID is the UUID assigned by PSM
+	name := d.Id()
+	url := config.Server + "/configs/network/v1/routingconfigs/" + name
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.AddCookie(&http.Cookie{Name: "sid", Value: config.SID})
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to import BGP config %s: HTTP %d %s: %s", name, resp.StatusCode, resp.Status, bodyBytes)
+	}
+
+	routingConfig := &RoutingConfig{}
+	if err := json.NewDecoder(resp.Body).Decode(routingConfig); err != nil {
+		return nil, err
+	}
+
+	d.SetId(routingConfig.Meta.UUID)
+	d.Set("name", routingConfig.Meta.Name)
+
+	return []*schema.ResourceData{d}, nil
+}
+
+func expandRoutingConfig(d *schema.ResourceData) *RoutingConfig {
+	routingConfig := &RoutingConfig{}
+	routingConfig.Kind = "RoutingConfig"
+	routingConfig.Meta.Name = d.Get("name").(string)
+	routingConfig.Spec.BGPConfig = &BGPConfig{
+		RouterID:          d.Get("router_id").(string),
+		ASNumber:          ASNumber{ASDotNotation: d.Get("as_number").(string)},
+		KeepaliveInterval: d.Get("keepalive_interval").(int),
+		Holdtime:          d.Get("holdtime").(int),
+		Neighbors:         []BGPNeighbor{},
+	}
+
+	for _, v := range d.Get("neighbor").([]interface{}) {
+		neighbor := v.(map[string]interface{})
+		routingConfig.Spec.BGPConfig.Neighbors = append(routingConfig.Spec.BGPConfig.Neighbors, BGPNeighbor{
+			Shutdown:              neighbor["shutdown"].(bool),
+			IPAddress:             neighbor["ip_address"].(string),
+			RemoteAS:              ASNumber{ASDotNotation: neighbor["remote_as"].(string)},
+			MultiHop:              neighbor["multi_hop"].(int),
+			EnableAddressFamilies: expandStringList(neighbor["address_families"].([]interface{})),
+			Password:              neighbor["password"].(string),
+			KeepaliveInterval:     neighbor["keepalive_interval"].(int),
+			Holdtime:              neighbor["holdtime"].(int),
+		})
+	}
+
+	return routingConfig
+}
+
+func flattenBGPNeighbors(neighbors []BGPNeighbor, passwords map[string]string) []interface{} {
+	result := make([]interface{}, 0, len(neighbors))
+	for _, neighbor := range neighbors {
+		result = append(result, map[string]interface{}{
+			"ip_address":         neighbor.IPAddress,
+			"remote_as":          neighbor.RemoteAS.ASDotNotation,
+			"password":           passwords[neighbor.IPAddress],
+			"keepalive_interval": neighbor.KeepaliveInterval,
+			"holdtime":           neighbor.Holdtime,
+			"multi_hop":          neighbor.MultiHop,
+			"address_families":   neighbor.EnableAddressFamilies,
+			"shutdown":           neighbor.Shutdown,
+		})
+	}
+	return result
+}
+
+// validateASN accepts a 4 byte AS number either as a plain integer or in asdot notation.
+func validateASN(val interface{}, key string) (warns []string, errs []error) {
+	v := val.(string)
+	parts := strings.Split(v, ".")
+	valid := false
+	switch len(parts) {
+	case 1:
+		n, err := strconv.ParseUint(parts[0], 10, 32)
+		valid = err == nil && n > 0
+	case 2:
+		high, errHigh := strconv.ParseUint(parts[0], 10, 16)
+		low, errLow := strconv.ParseUint(parts[1], 10, 16)
+		valid = errHigh == nil && errLow == nil && high+low > 0
+	}
+	if !valid {
+		errs = append(errs, fmt.Errorf("%q must be an AS number such as '65001' or '1.10', got: %s", key, v))
+	}
+	return
+}
+
+// validateBGPHoldtime accepts 0, which disables keepalives, or a hold time between 3 and 7200 seconds.
+func validateBGPHoldtime(val interface{}, key string) (warns []string, errs []error) {
+	v := val.(int)
+	if v != 0 && (v < 3 || v > 7200) {
+		errs = append(errs, fmt.Errorf("%q must be 0 or between 3 and 7200 seconds, got: %d", key, v))
+	}
+	return
+}
@@ -0,0 +1,136 @@
+package psm
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestValidateASN(t *testing.T) {
+	tests := []struct {
+		value   string
+		wantErr bool
+	}{
+		{"65001", false},
+		{"4294967295", false},
+		{"1.10", false},
+		{"0.1", false},
+		{"65535.65535", false},
+		{"0", true},
+		{"0.0", true},
+		{"4294967296", true},
+		{"65536.1", true},
+		{"1.2.3", true},
+		{"-1", true},
+		{"AS65001", true},
+		{"", true},
+	}
+
+	for _, tt := range tests {
+		_, errs := validateASN(tt.value, "as_number")
+		if (len(errs) > 0) != tt.wantErr {
+			t.Errorf("validateASN(%q) errors = %v, want error %v", tt.value, errs, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateBGPHoldtime(t *testing.T) {
+	tests := []struct {
+		value   int
+		wantErr bool
+	}{
+		{0, false},
+		{3, false},
+		{180, false},
+		{7200, false},
+		{1, true},
+		{2, true},
+		{7201, true},
+		{-1, true},
+	}
+
+	for _, tt := range tests {
+		_, errs := validateBGPHoldtime(tt.value, "holdtime")
+		if (len(errs) > 0) != tt.wantErr {
+			t.Errorf("validateBGPHoldtime(%d) errors = %v, want error %v", tt.value, errs, tt.wantErr)
+		}
+	}
+}
+
+func TestExpandRoutingConfig(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceBGPConfig().Schema, map[string]interface{}{
+		"name":      "default",
+		"router_id": "10.0.0.1",
+		"as_number": "65001",
+		"neighbor": []interface{}{
+			map[string]interface{}{
+				"ip_address":       "10.0.0.2",
+				"remote_as":        "65002",
+				"password":         "secret",
+				"address_families": []interface{}{"ipv4-unicast", "l2vpn-evpn"},
+			},
+		},
+	})
+
+	routingConfig := expandRoutingConfig(d)
+
+	want := &BGPConfig{
+		RouterID:          "10.0.0.1",
+		ASNumber:          ASNumber{ASDotNotation: "65001"},
+		KeepaliveInterval: 60,
+		Holdtime:          180,
+		Neighbors: []BGPNeighbor{{
+			IPAddress:             "10.0.0.2",
+			RemoteAS:              ASNumber{ASDotNotation: "65002"},
+			MultiHop:              1,
+			EnableAddressFamilies: []string{"ipv4-unicast", "l2vpn-evpn"},
+			Password:              "secret",
+			KeepaliveInterval:     60,
+			Holdtime:              180,
+		}},
+	}
+	if routingConfig.Meta.Name != "default" {
+		t.Errorf("name = %q, want %q", routingConfig.Meta.Name, "default")
+	}
+	if !reflect.DeepEqual(routingConfig.Spec.BGPConfig, want) {
+		t.Errorf("bgp-config = %+v, want %+v", routingConfig.Spec.BGPConfig, want)
+	}
+}
+
+func TestFlattenBGPNeighbors(t *testing.T) {
+	neighbors := []BGPNeighbor{
+		{IPAddress: "10.0.0.2", RemoteAS: ASNumber{ASDotNotation: "65002"}, MultiHop: 1, Holdtime: 180, KeepaliveInterval: 60, EnableAddressFamilies: []string{"ipv4-unicast"}},
+		{IPAddress: "10.0.0.3", RemoteAS: ASNumber{ASDotNotation: "1.10"}, MultiHop: 2, Shutdown: true, EnableAddressFamilies: []string{"l2vpn-evpn"}},
+	}
+	// PSM does not return passwords, the configured ones are matched by peer address
+	passwords := map[string]string{"10.0.0.3": "secret", "10.0.0.9": "stale"}
+
+	got := flattenBGPNeighbors(neighbors, passwords)
+
+	want := []interface{}{
+		map[string]interface{}{
+			"ip_address":         "10.0.0.2",
+			"remote_as":          "65002",
+			"password":           "",
+			"keepalive_interval": 60,
+			"holdtime":           180,
+			"multi_hop":          1,
+			"address_families":   []string{"ipv4-unicast"},
+			"shutdown":           false,
+		},
+		map[string]interface{}{
+			"ip_address":         "10.0.0.3",
+			"remote_as":          "1.10",
+			"password":           "secret",
+			"keepalive_interval": 0,
+			"holdtime":           0,
+			"multi_hop":          2,
+			"address_families":   []string{"l2vpn-evpn"},
+			"shutdown":           true,
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("flattenBGPNeighbors() = %v, want %v", got, want)
+	}
+}
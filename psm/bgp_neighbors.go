@@ -0,0 +1,226 @@
+package psm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceBGPNeighbors() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceBGPNeighborsRead,
+		Schema: map[string]*schema.Schema{
+			"virtual_router": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Name of the virtual router (VRF) to return BGP sessions for",
+			},
+			"dse": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return sessions of this DSE",
+			},
+			"wait_for_established": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Wait until every session is established before returning",
+			},
+			"timeout": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "5m",
+				ValidateFunc: validateDuration,
+				Description:  "How long to wait for sessions to be established",
+			},
+			"neighbors": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"dse": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"address": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"remote_as": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"state": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"uptime": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"prefixes_received": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"address_families": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
+			"established_count": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"all_established": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "True when at least one session was found and every session is established",
+			},
+		},
+	}
+}
+
+// BGPNeighborStatus is the state of one BGP session as reported by a DSE.
+type BGPNeighborStatus struct {
+	Instance         string   `json:"instance"`
+	NeighborAddress  string   `json:"neighbor-address"`
+	RemoteAS         ASNumber `json:"remote-as"`
+	State            string   `json:"state"`
+	Uptime           string   `json:"uptime"`
+	PrefixesReceived int      `json:"prefixes-received"`
+	AddressFamilies  []string `json:"enabled-address-families"`
+}
+
+func dataSourceBGPNeighborsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	config := m.(*Config)
+	vrfName := d.Get("virtual_router").(string)
+
+	neighbors, err := listBGPNeighbors(ctx, config, vrfName, d.Get("dse").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if d.Get("wait_for_established").(bool) && !bgpNeighborsEstablished(neighbors) {
+		waitTimeout, _ := time.ParseDuration(d.Get("timeout").(string))
+		timeout := time.After(waitTimeout)
+		ticker := time.NewTicker(5 * time.Second)
+		defer ticker.Stop()
+
+	wait:
+		for {
+			select {
+			case <-timeout:
+				return diag.Errorf("timeout waiting for BGP sessions of VRF %s to be established: %s", vrfName, describeBGPNeighbors(neighbors))
+			case <-ticker.C:
+				if neighbors, err = listBGPNeighbors(ctx, config, vrfName, d.Get("dse").(string)); err != nil {
+					return diag.FromErr(err)
+				}
+				if bgpNeighborsEstablished(neighbors) {
+					break wait
+				}
+				log.Printf("[DEBUG] Waiting for BGP sessions of VRF %s: %s", vrfName, describeBGPNeighbors(neighbors))
+			case <-ctx.Done():
+				return diag.FromErr(ctx.Err())
+			}
+		}
+	}
+
+	result := make([]interface{}, 0, len(neighbors))
+	established := 0
+	for _, neighbor := range neighbors {
+		if isBGPEstablished(neighbor) {
+			established++
+		}
+		result = append(result, map[string]interface{}{
+			"dse":               neighbor.Instance,
+			"address":           neighbor.NeighborAddress,
+			"remote_as":         neighbor.RemoteAS.ASDotNotation,
+			"state":             neighbor.State,
+			"uptime":            neighbor.Uptime,
+			"prefixes_received": neighbor.PrefixesReceived,
+			"address_families":  neighbor.AddressFamilies,
+		})
+	}
+
+	if err := d.Set("neighbors", result); err != nil {
+		return diag.FromErr(err)
+	}
+	d.Set("established_count", established)
+	d.Set("all_established", bgpNeighborsEstablished(neighbors))
+
+	d.SetId(vrfName)
+
+	return nil
+}
+
+func listBGPNeighbors(ctx context.Context, config *Config, vrfName, dse string) ([]BGPNeighborStatus, error) {
+	query := url.Values{}
+	query.Set("virtual-router", vrfName)
+	if dse != "" {
+		query.Set("instance", dse)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", config.Server+"/routing/v1/neighbors?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.AddCookie(&http.Cookie{Name: "sid", Value: config.SID})
+
+	resp, err := config.Client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to list BGP neighbors of VRF %s: HTTP %d %s: %s", vrfName, resp.StatusCode, resp.Status, bodyBytes)
+	}
+
+	list := struct {
+		Items []BGPNeighborStatus `json:"items"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+func isBGPEstablished(neighbor BGPNeighborStatus) bool {
+	return strings.EqualFold(neighbor.State, "established")
+}
+
+func bgpNeighborsEstablished(neighbors []BGPNeighborStatus) bool {
+	if len(neighbors) == 0 {
+		return false
+	}
+	for _, neighbor := range neighbors {
+		if !isBGPEstablished(neighbor) {
+			return false
+		}
+	}
+	return true
+}
+
+func describeBGPNeighbors(neighbors []BGPNeighborStatus) string {
+	if len(neighbors) == 0 {
+		return "no sessions found"
+	}
+	states := make([]string, 0, len(neighbors))
+	for _, neighbor := range neighbors {
+		states = append(states, fmt.Sprintf("%s on %s is %s", neighbor.NeighborAddress, neighbor.Instance, neighbor.State))
+	}
+	return strings.Join(states, ", ")
+}
@@ -0,0 +1,118 @@
+package psm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestBGPNeighborsEstablished(t *testing.T) {
+	established := BGPNeighborStatus{Instance: "dse-1", NeighborAddress: "10.0.0.2", State: "Established"}
+	idle := BGPNeighborStatus{Instance: "dse-2", NeighborAddress: "10.0.0.2", State: "idle"}
+
+	tests := []struct {
+		name      string
+		neighbors []BGPNeighborStatus
+		want      bool
+		wantDesc  string
+	}{
+		{"no sessions", nil, false, "no sessions found"},
+		{"all established", []BGPNeighborStatus{established}, true, "10.0.0.2 on dse-1 is Established"},
+		{"one idle", []BGPNeighborStatus{established, idle}, false, "10.0.0.2 on dse-1 is Established, 10.0.0.2 on dse-2 is idle"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bgpNeighborsEstablished(tt.neighbors); got != tt.want {
+				t.Errorf("bgpNeighborsEstablished() = %v, want %v", got, tt.want)
+			}
+			if got := describeBGPNeighbors(tt.neighbors); got != tt.wantDesc {
+				t.Errorf("describeBGPNeighbors() = %q, want %q", got, tt.wantDesc)
+			}
+		})
+	}
+}
+
+func TestListBGPNeighbors(t *testing.T) {
+	var query map[string][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/routing/v1/neighbors" {
+			http.NotFound(w, r)
+			return
+		}
+		query = r.URL.Query()
+		w.Write([]byte(`{"items": [{"instance": "dse-1", "neighbor-address": "10.0.0.2", "remote-as": {"as-dot-notation": "65002"}, "state": "established"}]}`))
+	}))
+	defer server.Close()
+	config := &Config{Server: server.URL}
+
+	neighbors, err := listBGPNeighbors(context.Background(), config, "blue", "dse-1")
+	if err != nil {
+		t.Fatalf("listBGPNeighbors() error = %v", err)
+	}
+	if len(neighbors) != 1 || neighbors[0].RemoteAS.ASDotNotation != "65002" {
+		t.Errorf("listBGPNeighbors() = %+v", neighbors)
+	}
+	if got := query["virtual-router"]; len(got) != 1 || got[0] != "blue" {
+		t.Errorf("virtual-router query = %q, want blue", got)
+	}
+	if got := query["instance"]; len(got) != 1 || got[0] != "dse-1" {
+		t.Errorf("instance query = %q, want dse-1", got)
+	}
+
+	if _, err := listBGPNeighbors(context.Background(), config, "blue", ""); err != nil {
+		t.Fatalf("listBGPNeighbors() error = %v", err)
+	}
+	if _, ok := query["instance"]; ok {
+		t.Errorf("instance query = %q, want it left out without a DSE", query["instance"])
+	}
+}
+
+func TestDataSourceBGPNeighborsRead(t *testing.T) {
+	config := newTestServer(t, map[string]string{
+		"/routing/v1/neighbors": `{"items": [
+			{"instance": "dse-1", "neighbor-address": "10.0.0.2", "state": "established", "prefixes-received": 12},
+			{"instance": "dse-2", "neighbor-address": "10.0.0.2", "state": "active"}
+		]}`,
+	})
+
+	d := schema.TestResourceDataRaw(t, dataSourceBGPNeighbors().Schema, map[string]interface{}{"virtual_router": "blue"})
+	if diags := dataSourceBGPNeighborsRead(context.Background(), d, config); diags.HasError() {
+		t.Fatalf("read error = %v", diags)
+	}
+
+	if got := d.Get("neighbors.#").(int); got != 2 {
+		t.Errorf("neighbors = %d, want 2", got)
+	}
+	if got := d.Get("neighbors.0.prefixes_received").(int); got != 12 {
+		t.Errorf("prefixes_received = %d, want 12", got)
+	}
+	if got := d.Get("established_count").(int); got != 1 {
+		t.Errorf("established_count = %d, want 1", got)
+	}
+	if d.Get("all_established").(bool) {
+		t.Errorf("all_established = true, want false")
+	}
+}
+
+func TestDataSourceBGPNeighborsReadTimeout(t *testing.T) {
+	config := newTestServer(t, map[string]string{
+		"/routing/v1/neighbors": `{"items": [{"instance": "dse-1", "neighbor-address": "10.0.0.2", "state": "connect"}]}`,
+	})
+
+	d := schema.TestResourceDataRaw(t, dataSourceBGPNeighbors().Schema, map[string]interface{}{
+		"virtual_router":       "blue",
+		"wait_for_established": true,
+		"timeout":              "10ms",
+	})
+	diags := dataSourceBGPNeighborsRead(context.Background(), d, config)
+	if !diags.HasError() {
+		t.Fatal("read error = nil, want a timeout")
+	}
+	if want := "timeout waiting for BGP sessions of VRF blue to be established: 10.0.0.2 on dse-1 is connect"; diags[0].Summary != want {
+		t.Errorf("error = %q, want %q", diags[0].Summary, want)
+	}
+}
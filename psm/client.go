@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"sync"
 )
 
 type Config struct {
@@ -14,6 +15,11 @@ type Config struct {
 	Server   string
 	SID      string // Store the SID cookie passed back from PSM Authentication
 	Insecure bool   // Skip SSL verification if using an unsigned SSL Certificate
+
+	// plannedIPCollections records the ip_collections planned for each collection name during a plan, so that
+	// nesting cycles between collections of the same configuration are found before anything is applied. Entries
+	// are overwritten on every plan of the collection, and dropped when it is renamed or deleted.
+	plannedIPCollections sync.Map
 }
 
 func (c *Config) Authenticate() error {
@@ -0,0 +1,1098 @@
+package psm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// exportKind describes how a PSM kind is listed, imported and rendered as a Terraform resource.
+type exportKind struct {
+	kind         string
+	resourceType string
+	path         string
+	// refAttr is the attribute other resources use to reference this one, e.g. "name" in psm_vrf.example.name
+	refAttr hcl.Traversal
+	// importID returns the ID accepted by the resource importer for the given object
+	importID func(meta exportMeta) string
+	render   func(x *exporter, raw json.RawMessage, address string, body *hclwrite.Body) error
+}
+
+// exportMeta holds the subset of object metadata shared by every PSM kind.
+type exportMeta struct {
+	Name        string `json:"name"`
+	Tenant      string `json:"tenant"`
+	Namespace   string `json:"namespace"`
+	DisplayName string `json:"display-name"`
+}
+
+type exportObject struct {
+	Meta exportMeta `json:"meta"`
+}
+
+type exportList struct {
+	Items []json.RawMessage `json:"items"`
+}
+
+// exportKinds lists every supported kind. Kinds are exported in this order so that the generated files read
+// top-down from the objects that are referenced to the objects that reference them.
+var exportKinds = []*exportKind{
+	{
+		kind:         "virtualrouter",
+		resourceType: "psm_vrf",
+		path:         "/configs/network/v1/tenant/default/virtualrouters",
+		refAttr:      refAttr("name"),
+		importID:     importByName,
+		render:       renderVRF,
+	},
+	{
+		kind:         "routingconfig",
+		resourceType: "psm_bgp_config",
+		path:         "/configs/network/v1/routingconfigs",
+		refAttr:      refAttr("name"),
+		importID:     importByName,
+		render:       renderBGPConfig,
+	},
+	{
+		kind:         "ipampolicy",
+		resourceType: "psm_ipam_policy",
+		path:         "/configs/network/v1/tenant/default/ipam-policies",
+		refAttr:      refAttr("name"),
+		importID:     importByName,
+		render:       renderIPAMPolicy,
+	},
+	{
+		kind:         "ipcollection",
+		resourceType: "psm_ipcollection",
+		path:         "/configs/network/v1/tenant/default/ipcollections",
+		refAttr:      refAttr("name"),
+		importID:     importByName,
+		render:       renderIPCollection,
+	},
+	{
+		kind:         "workloadgroup",
+		resourceType: "psm_workloadgroup",
+		path:         "/configs/workload/v1/tenant/default/workloadgroups",
+		refAttr:      refAttr("name"),
+		importID:     importByName,
+		render:       renderWorkloadGroup,
+	},
+	{
+		kind:         "app",
+		resourceType: "psm_app",
+		path:         "/configs/security/v1/tenant/default/apps",
+		refAttr:      hcl.Traversal{hcl.TraverseAttr{Name: "meta"}, hcl.TraverseIndex{Key: cty.NumberIntVal(0)}, hcl.TraverseAttr{Name: "name"}},
+		importID:     importByName,
+		render:       renderApp,
+	},
+	{
+		kind:         "networksecuritypolicy",
+		resourceType: "psm_rules",
+		path:         "/configs/security/v1/tenant/default/networksecuritypolicies",
+		refAttr:      refAttr("policy_name"),
+		importID:     importByName,
+		render:       renderSecurityPolicy,
+	},
+	{
+		kind:         "natpolicy",
+		resourceType: "psm_nat_policy",
+		path:         "/configs/network/v1/tenant/default/natpolicies",
+		refAttr:      refAttr("name"),
+		importID:     importByName,
+		render:       renderNATPolicy,
+	},
+	{
+		kind:         "ipsecpolicy",
+		resourceType: "psm_ipsec_policy",
+		path:         "/configs/security/v1/tenant/default/ipsecpolicies",
+		refAttr:      refAttr("name"),
+		importID:     importByName,
+		render:       renderIPSecPolicy,
+	},
+	{
+		kind:         "mirrorsession",
+		resourceType: "psm_mirror_session",
+		path:         "/configs/monitoring/v1/tenant/default/MirrorSession",
+		refAttr:      refAttr("name"),
+		importID:     importByName,
+		render:       renderMirrorSession,
+	},
+	{
+		kind:         "fwlogpolicy",
+		resourceType: "psm_syslog_export_policy",
+		path:         "/configs/monitoring/v1/tenant/default/fwlogPolicy",
+		refAttr:      refAttr("name"),
+		importID:     importByName,
+		render:       renderSyslogPolicy,
+	},
+	{
+		kind:         "flowexportpolicy",
+		resourceType: "psm_flow_export_policy",
+		path:         "/configs/monitoring/v1/tenant/default/flowExportPolicy",
+		refAttr:      refAttr("name"),
+		importID:     importByName,
+		render:       renderFlowExportPolicy,
+	},
+	{
+		kind:         "network",
+		resourceType: "psm_network",
+		path:         "/configs/network/v1/tenant/default/networks",
+		refAttr:      refAttr("name"),
+		importID:     importByName,
+		render:       renderNetwork,
+	},
+	{
+		kind:         "user",
+		resourceType: "psm_user",
+		path:         "/configs/auth/v1/tenant/default/users",
+		refAttr:      refAttr("name"),
+		importID: func(meta exportMeta) string {
+			return defaultString(meta.Tenant, "default") + "/" + defaultString(meta.Namespace, "default") + "/" + meta.Name
+		},
+		render: renderUser,
+	},
+	{
+		kind:         "role",
+		resourceType: "psm_user_role",
+		path:         "/configs/auth/v1/tenant/default/roles",
+		refAttr:      refAttr("name"),
+		importID: func(meta exportMeta) string {
+			return defaultString(meta.Tenant, "default") + "/" + defaultString(meta.Namespace, "default") + "/" + meta.Name
+		},
+		render: renderRole,
+	},
+	{
+		kind:         "rolebinding",
+		resourceType: "psm_role_binding",
+		path:         "/configs/auth/v1/tenant/default/role-bindings",
+		refAttr:      refAttr("name"),
+		importID: func(meta exportMeta) string {
+			return defaultString(meta.Tenant, "default") + "/" + meta.Name
+		},
+		render: renderRoleBinding,
+	},
+}
+
+// exporter holds the state shared while rendering objects, most importantly the Terraform address assigned to
+// every exported object so that references between objects can be written as expressions instead of literals.
+type exporter struct {
+	config    *Config
+	kinds     map[string]*exportKind
+	addresses map[string]map[string]string
+	used      map[string]map[string]bool
+}
+
+func newExporter(config *Config) *exporter {
+	kinds := make(map[string]*exportKind, len(exportKinds))
+	for _, k := range exportKinds {
+		kinds[k.kind] = k
+	}
+	return &exporter{
+		config:    config,
+		kinds:     kinds,
+		addresses: make(map[string]map[string]string),
+		used:      make(map[string]map[string]bool),
+	}
+}
+
+// Export reads the named object of the given kind from PSM and writes it to w as Terraform HCL, preceded by an
+// import block so that the existing object can be adopted into state without being recreated.
+func Export(ctx context.Context, config *Config, kind, name string, w io.Writer) error {
+	k := findExportKind(kind)
+	if k == nil {
+		return fmt.Errorf("unsupported kind %q, supported kinds are: %s", kind, strings.Join(ExportKinds(), ", "))
+	}
+
+	x := newExporter(config)
+	raw, err := x.get(ctx, k.path+"/"+name)
+	if err != nil {
+		return fmt.Errorf("failed to read %s %s: %v", kind, name, err)
+	}
+
+	f := hclwrite.NewEmptyFile()
+	if err := x.renderObject(k, raw, f.Body()); err != nil {
+		return err
+	}
+
+	_, err = w.Write(hclwrite.Format(f.Bytes()))
+	return err
+}
+
+// ExportAll walks every supported kind and writes one <resource type>.tf file per kind into dir. References
+// between exported objects are resolved to Terraform addresses. Kinds that cannot be listed are skipped and
+// reported in the returned error once every other kind has been written.
+func ExportAll(ctx context.Context, config *Config, dir string) error {
+	x := newExporter(config)
+	var errs []error
+
+	items := make(map[string][]json.RawMessage)
+	for _, k := range exportKinds {
+		raw, err := x.get(ctx, k.path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to list %s: %v", k.kind, err))
+			continue
+		}
+
+		list := exportList{}
+		if err := json.Unmarshal(raw, &list); err != nil {
+			errs = append(errs, fmt.Errorf("failed to decode %s list: %v", k.kind, err))
+			continue
+		}
+
+		sort.SliceStable(list.Items, func(i, j int) bool {
+			return objectMeta(list.Items[i]).Name < objectMeta(list.Items[j]).Name
+		})
+		for _, item := range list.Items {
+			x.assignAddress(k, objectMeta(item))
+		}
+		items[k.kind] = list.Items
+	}
+
+	for _, k := range exportKinds {
+		if len(items[k.kind]) == 0 {
+			continue
+		}
+
+		f := hclwrite.NewEmptyFile()
+		for _, item := range items[k.kind] {
+			if err := x.renderObject(k, item, f.Body()); err != nil {
+				errs = append(errs, fmt.Errorf("failed to export %s %s: %v", k.kind, objectMeta(item).Name, err))
+			}
+		}
+
+		filename := filepath.Join(dir, k.resourceType+".tf")
+		log.Printf("[DEBUG] Writing %d %s objects to %s", len(items[k.kind]), k.kind, filename)
+		if err := os.WriteFile(filename, hclwrite.Format(f.Bytes()), 0644); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// ExportKinds returns the list of kinds supported by Export.
+func ExportKinds() []string {
+	kinds := make([]string, len(exportKinds))
+	for i, k := range exportKinds {
+		kinds[i] = k.kind
+	}
+	return kinds
+}
+
+func findExportKind(kind string) *exportKind {
+	for _, k := range exportKinds {
+		if k.kind == kind {
+			return k
+		}
+	}
+	return nil
+}
+
+func (x *exporter) get(ctx context.Context, path string) (json.RawMessage, error) {
+	client := x.config.Client()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", x.config.Server+path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.AddCookie(&http.Cookie{Name: "sid", Value: x.config.SID})
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d %s: %s", resp.StatusCode, resp.Status, bodyBytes)
+	}
+
+	return bodyBytes, nil
+}
+
+func (x *exporter) renderObject(k *exportKind, raw json.RawMessage, body *hclwrite.Body) error {
+	meta := objectMeta(raw)
+	address := x.assignAddress(k, meta)
+	log.Printf("[DEBUG] Exporting %s %s as %s.%s", k.kind, meta.Name, k.resourceType, address)
+
+	appendImportBlock(body, k.resourceType, address, k.importID(meta))
+	if err := k.render(x, raw, address, body); err != nil {
+		return err
+	}
+	body.AppendNewline()
+	return nil
+}
+
+// assignAddress returns the Terraform resource name for the object, allocating a unique one on first use.
+// Objects created with a display name get a generated PSM name, so the display name is preferred when set.
+func (x *exporter) assignAddress(k *exportKind, meta exportMeta) string {
+	if x.addresses[k.kind] == nil {
+		x.addresses[k.kind] = make(map[string]string)
+		x.used[k.kind] = make(map[string]bool)
+	}
+	if address, ok := x.addresses[k.kind][meta.Name]; ok {
+		return address
+	}
+
+	base := terraformResourceName(defaultString(meta.DisplayName, meta.Name))
+	address := base
+	for i := 2; x.used[k.kind][address]; i++ {
+		address = base + "_" + strconv.Itoa(i)
+	}
+
+	x.addresses[k.kind][meta.Name] = address
+	x.used[k.kind][address] = true
+	return address
+}
+
+// refTokens returns an expression referencing the exported object of the given kind, or the literal name when
+// the object is not part of the export.
+func (x *exporter) refTokens(kind, name string) hclwrite.Tokens {
+	if address, ok := x.addresses[kind][name]; ok {
+		k := x.kinds[kind]
+		traversal := hcl.Traversal{
+			hcl.TraverseRoot{Name: k.resourceType},
+			hcl.TraverseAttr{Name: address},
+		}
+		return hclwrite.TokensForTraversal(append(traversal, k.refAttr...))
+	}
+	return hclwrite.TokensForValue(cty.StringVal(name))
+}
+
+func (x *exporter) setRef(body *hclwrite.Body, key, kind, name string) {
+	if name != "" {
+		body.SetAttributeRaw(key, x.refTokens(kind, name))
+	}
+}
+
+func (x *exporter) setRefList(body *hclwrite.Body, key, kind string, names []string) {
+	if len(names) == 0 {
+		return
+	}
+	elems := make([]hclwrite.Tokens, len(names))
+	for i, name := range names {
+		elems[i] = x.refTokens(kind, name)
+	}
+	body.SetAttributeRaw(key, hclwrite.TokensForTuple(elems))
+}
+
+func renderSecurityPolicy(x *exporter, raw json.RawMessage, address string, body *hclwrite.Body) error {
+	policy := &NetworkSecurityPolicy{}
+	if err := json.Unmarshal(raw, policy); err != nil {
+		return err
+	}
+
+	resource := body.AppendNewBlock("resource", []string{"psm_rules", address}).Body()
+	resource.SetAttributeValue("policy_name", cty.StringVal(policy.Meta.Name))
+	if policy.Meta.Tenant != "" && policy.Meta.Tenant != "default" {
+		resource.SetAttributeValue("tenant", cty.StringVal(policy.Meta.Tenant))
+	}
+	if len(policy.Spec.PolicyDistributionTargets) > 1 || (len(policy.Spec.PolicyDistributionTargets) == 1 && policy.Spec.PolicyDistributionTargets[0] != "default") {
+		setStringListIfNotEmpty(resource, "policy_distribution_targets", policy.Spec.PolicyDistributionTargets)
+	}
+	setStringIfNotEmpty(resource, "address_family", policy.Spec.AddressFamily)
+
+	for _, rule := range policy.Spec.Rules {
+		resource.AppendNewline()
+		ruleBody := resource.AppendNewBlock("rule", nil).Body()
+		setStringIfNotEmpty(ruleBody, "rule_name", rule.Name)
+		ruleBody.SetAttributeValue("action", cty.StringVal(rule.Action))
+		setStringIfNotEmpty(ruleBody, "description", rule.Description)
+		x.setRefList(ruleBody, "apps", "app", rule.Apps)
+		setStringListIfNotEmpty(ruleBody, "from_ip_addresses", rule.FromIPAddresses)
+		setStringListIfNotEmpty(ruleBody, "to_ip_addresses", rule.ToIPAddresses)
+		x.setRefList(ruleBody, "from_ip_collections", "ipcollection", rule.FromIPCollections)
+		x.setRefList(ruleBody, "to_ip_collections", "ipcollection", rule.ToIPCollections)
+		x.setRefList(ruleBody, "from_workloadgroups", "workloadgroup", rule.FromWorkloadGroup)
+		x.setRefList(ruleBody, "to_workloadgroups", "workloadgroup", rule.ToWorkloadGroup)
+		setStringIfNotEmpty(ruleBody, "rule_profile", rule.RuleProfile)
+		if rule.Disable {
+			ruleBody.SetAttributeValue("disable", cty.True)
+		}
+		setStringMapIfNotEmpty(ruleBody, "labels", rule.Labels)
+
+		for _, pp := range rule.ProtoPorts {
+			ppBody := ruleBody.AppendNewBlock("proto_ports", nil).Body()
+			ppBody.SetAttributeValue("protocol", cty.StringVal(pp.Protocol))
+			setStringIfNotEmpty(ppBody, "ports", pp.Ports)
+		}
+	}
+
+	return nil
+}
+
+func renderNetwork(x *exporter, raw json.RawMessage, address string, body *hclwrite.Body) error {
+	network := &Network{}
+	if err := json.Unmarshal(raw, network); err != nil {
+		return err
+	}
+
+	resource := body.AppendNewBlock("resource", []string{"psm_network", address}).Body()
+	resource.SetAttributeValue("name", cty.StringVal(network.Meta.Name))
+	setStringIfNotEmpty(resource, "tenant", network.Meta.Tenant)
+	if network.Spec.Type == "routed" {
+		resource.SetAttributeValue("type", cty.StringVal(network.Spec.Type))
+	}
+	if network.Spec.VlanID != 0 {
+		resource.SetAttributeValue("vlan_id", cty.NumberIntVal(int64(network.Spec.VlanID)))
+	}
+	for _, key := range []struct {
+		attribute string
+		value     interface{}
+	}{
+		{"ipv4_subnet", network.Spec.Ipv4Subnet},
+		{"ipv4_gateway", network.Spec.Ipv4Gateway},
+		{"ipv6_subnet", network.Spec.Ipv6Subnet},
+		{"ipv6_gateway", network.Spec.Ipv6Gateway},
+	} {
+		if value, ok := key.value.(string); ok {
+			setStringIfNotEmpty(resource, key.attribute, value)
+		}
+	}
+	if vni, ok := network.Spec.VxlanVni.(float64); ok && vni != 0 {
+		resource.SetAttributeValue("vxlan_vni", cty.NumberIntVal(int64(vni)))
+	}
+	if ipamPolicy, ok := network.Spec.IpamPolicy.(string); ok {
+		x.setRef(resource, "ipam_policy", "ipampolicy", ipamPolicy)
+	}
+	x.setRef(resource, "virtual_router", "virtualrouter", network.Spec.VirtualRouter)
+	x.setRefList(resource, "ingress_security_policies", "networksecuritypolicy", network.Spec.IngressSecurityPolicy)
+	x.setRefList(resource, "egress_security_policies", "networksecuritypolicy", network.Spec.EgressSecurityPolicy)
+	setStringIfNotEmpty(resource, "connection_tracking_mode", network.Spec.ConnectionTracking)
+	setStringIfNotEmpty(resource, "allow_session_reuse", network.Spec.AllowSessionReuse)
+	if network.Spec.ServiceBypass {
+		resource.SetAttributeValue("service_bypass", cty.True)
+	}
+	setStringIfNotEmpty(resource, "ip_fragments_forwarding", network.Spec.IpFragmentsForwarding)
+	for _, orchestrator := range network.Spec.Orchestrators {
+		orchestratorBody := resource.AppendNewBlock("orchestrators", nil).Body()
+		orchestratorBody.SetAttributeValue("name", cty.StringVal(orchestrator.Name))
+		orchestratorBody.SetAttributeValue("namespace", cty.StringVal(orchestrator.Namespace))
+	}
+	if cps := network.Spec.FirewallProfile.MaximumCpsPerDistributedServicesEntity; !isUnlimitedDSELimit(cps) {
+		resource.SetAttributeValue("max_cps_per_dse", cty.NumberIntVal(int64(cps)))
+	}
+	if sessions := network.Spec.FirewallProfile.MaximumSessionsPerDistributedServicesEntity; !isUnlimitedDSELimit(sessions) {
+		resource.SetAttributeValue("max_sessions_per_dse", cty.NumberIntVal(int64(sessions)))
+	}
+	if len(network.Spec.IngressMirrorSession) > 0 {
+		x.setRef(resource, "ingress_mirror_session", "mirrorsession", fmt.Sprintf("%v", network.Spec.IngressMirrorSession[0]))
+	}
+	if len(network.Spec.EgressMirrorSession) > 0 {
+		x.setRef(resource, "egress_mirror_session", "mirrorsession", fmt.Sprintf("%v", network.Spec.EgressMirrorSession[0]))
+	}
+	if network.Spec.Type == "routed" && network.Spec.RouteImportExport != nil {
+		raw, err := json.Marshal(network.Spec.RouteImportExport)
+		if err != nil {
+			return err
+		}
+		rie := &RouteImportExport{}
+		if err := json.Unmarshal(raw, rie); err != nil {
+			return err
+		}
+		renderRouteImportExport(resource, rie)
+	}
+
+	return nil
+}
+
+func renderRouteImportExport(body *hclwrite.Body, rie *RouteImportExport) {
+	if rie.RDAuto && len(rie.ExportRTs) == 0 && len(rie.ImportRTs) == 0 {
+		return
+	}
+
+	rieBody := body.AppendNewBlock("route_import_export", nil).Body()
+	setStringIfNotEmpty(rieBody, "address_family", rie.AddressFamily)
+	rieBody.SetAttributeValue("rd_auto", cty.BoolVal(rie.RDAuto))
+	renderRD := func(name string, rd *RouteDistinguisher) {
+		if rd == nil {
+			return
+		}
+		rdBody := rieBody.AppendNewBlock(name, nil).Body()
+		rdBody.SetAttributeValue("type", cty.StringVal(rd.Type))
+		rdBody.SetAttributeValue("admin_value", cty.NumberIntVal(int64(rd.AdminValue)))
+		rdBody.SetAttributeValue("assigned_value", cty.NumberIntVal(int64(rd.AssignedValue)))
+	}
+	if !rie.RDAuto {
+		renderRD("rd", rie.RD)
+	}
+	for _, rd := range rie.ExportRTs {
+		renderRD("export_rts", rd)
+	}
+	for _, rd := range rie.ImportRTs {
+		renderRD("import_rts", rd)
+	}
+}
+
+func renderVRF(x *exporter, raw json.RawMessage, address string, body *hclwrite.Body) error {
+	vrf := &VRF{}
+	if err := json.Unmarshal(raw, vrf); err != nil {
+		return err
+	}
+
+	resource := body.AppendNewBlock("resource", []string{"psm_vrf", address}).Body()
+	resource.SetAttributeValue("name", cty.StringVal(vrf.Meta.Name))
+	x.setRefList(resource, "ingress_security_policy", "networksecuritypolicy", vrf.Spec.IngressSecurityPolicy)
+	x.setRefList(resource, "egress_security_policy", "networksecuritypolicy", vrf.Spec.EgressSecurityPolicy)
+	setStringIfNotEmpty(resource, "connection_tracking_mode", vrf.Spec.ConnectionTracking)
+	setStringIfNotEmpty(resource, "allow_session_reuse", vrf.Spec.AllowSessionReuse)
+	setStringIfNotEmpty(resource, "ip_fragments_forwarding", vrf.Spec.IpFragmentsForwarding)
+	x.setRefList(resource, "ingress_nat_policy", "natpolicy", vrf.Spec.IngressNatPolicy)
+	x.setRefList(resource, "egress_nat_policy", "natpolicy", vrf.Spec.EgressNatPolicy)
+	x.setRefList(resource, "ipsec_policy", "ipsecpolicy", vrf.Spec.IpsecPolicy)
+	x.setRefList(resource, "flow_export_policy", "flowexportpolicy", vrf.Spec.FlowExportPolicy)
+	if vrf.Spec.MaximumCpsPerNetworkPerDistributedServicesEntity != 0 {
+		resource.SetAttributeValue("maximum_cps_per_network", cty.NumberIntVal(int64(vrf.Spec.MaximumCpsPerNetworkPerDistributedServicesEntity)))
+	}
+	if vrf.Spec.MaximumSessionsPerNetworkPerDistributedServicesEntity != 0 {
+		resource.SetAttributeValue("maximum_sessions_per_network", cty.NumberIntVal(int64(vrf.Spec.MaximumSessionsPerNetworkPerDistributedServicesEntity)))
+	}
+	if vrf.Spec.RouteImportExport != nil {
+		renderRouteImportExport(resource, vrf.Spec.RouteImportExport)
+	}
+	for _, route := range vrf.Spec.StaticRoutes {
+		routeBody := resource.AppendNewBlock("static_route", nil).Body()
+		routeBody.SetAttributeValue("prefix", cty.StringVal(route.Prefix))
+		routeBody.SetAttributeValue("next_hop", cty.StringVal(route.NextHop))
+		if route.AdminDistance > 1 {
+			routeBody.SetAttributeValue("admin_distance", cty.NumberIntVal(int64(route.AdminDistance)))
+		}
+	}
+
+	return nil
+}
+
+func renderBGPConfig(x *exporter, raw json.RawMessage, address string, body *hclwrite.Body) error {
+	routingConfig := &RoutingConfig{}
+	if err := json.Unmarshal(raw, routingConfig); err != nil {
+		return err
+	}
+	bgp := routingConfig.Spec.BGPConfig
+	if bgp == nil {
+		bgp = &BGPConfig{}
+	}
+
+	// PSM does not return neighbor passwords, they have to be added to the generated configuration by hand
+	resource := body.AppendNewBlock("resource", []string{"psm_bgp_config", address}).Body()
+	resource.SetAttributeValue("name", cty.StringVal(routingConfig.Meta.Name))
+	resource.SetAttributeValue("router_id", cty.StringVal(bgp.RouterID))
+	resource.SetAttributeValue("as_number", cty.StringVal(bgp.ASNumber.ASDotNotation))
+	resource.SetAttributeValue("keepalive_interval", cty.NumberIntVal(int64(bgp.KeepaliveInterval)))
+	resource.SetAttributeValue("holdtime", cty.NumberIntVal(int64(bgp.Holdtime)))
+	for _, neighbor := range bgp.Neighbors {
+		neighborBody := resource.AppendNewBlock("neighbor", nil).Body()
+		neighborBody.SetAttributeValue("ip_address", cty.StringVal(neighbor.IPAddress))
+		neighborBody.SetAttributeValue("remote_as", cty.StringVal(neighbor.RemoteAS.ASDotNotation))
+		neighborBody.SetAttributeValue("keepalive_interval", cty.NumberIntVal(int64(neighbor.KeepaliveInterval)))
+		neighborBody.SetAttributeValue("holdtime", cty.NumberIntVal(int64(neighbor.Holdtime)))
+		neighborBody.SetAttributeValue("multi_hop", cty.NumberIntVal(int64(neighbor.MultiHop)))
+		setStringList(neighborBody, "address_families", neighbor.EnableAddressFamilies)
+		if neighbor.Shutdown {
+			neighborBody.SetAttributeValue("shutdown", cty.True)
+		}
+	}
+
+	return nil
+}
+
+func renderIPAMPolicy(x *exporter, raw json.RawMessage, address string, body *hclwrite.Body) error {
+	policy := &IPAMPolicy{}
+	if err := json.Unmarshal(raw, policy); err != nil {
+		return err
+	}
+
+	resource := body.AppendNewBlock("resource", []string{"psm_ipam_policy", address}).Body()
+	resource.SetAttributeValue("name", cty.StringVal(policy.Meta.Name))
+	for _, server := range policy.Spec.DHCPRelay.Servers {
+		serverBody := resource.AppendNewBlock("dhcp_server", nil).Body()
+		serverBody.SetAttributeValue("ip_address", cty.StringVal(server.IPAddress))
+		if server.VirtualRouter != "" && server.VirtualRouter != "default" {
+			x.setRef(serverBody, "virtual_router", "virtualrouter", server.VirtualRouter)
+		}
+	}
+
+	return nil
+}
+
+func renderIPCollection(x *exporter, raw json.RawMessage, address string, body *hclwrite.Body) error {
+	ipCollection := &IPCollection{}
+	if err := json.Unmarshal(raw, ipCollection); err != nil {
+		return err
+	}
+
+	resource := body.AppendNewBlock("resource", []string{"psm_ipcollection", address}).Body()
+	resource.SetAttributeValue("display_name", cty.StringVal(defaultString(ipCollection.Meta.DisplayName, ipCollection.Meta.Name)))
+	setStringListIfNotEmpty(resource, "addresses", ipCollection.Spec.Addresses)
+	x.setRefList(resource, "ip_collections", "ipcollection", ipCollection.Spec.IPCollections)
+	setStringIfNotEmpty(resource, "address_family", ipCollection.Spec.AddressFamily)
+
+	return nil
+}
+
+func renderWorkloadGroup(x *exporter, raw json.RawMessage, address string, body *hclwrite.Body) error {
+	workloadgroup := &WorkloadGroup{}
+	if err := json.Unmarshal(raw, workloadgroup); err != nil {
+		return err
+	}
+
+	resource := body.AppendNewBlock("resource", []string{"psm_workloadgroup", address}).Body()
+	resource.SetAttributeValue("name", cty.StringVal(workloadgroup.Meta.Name))
+	for _, selector := range workloadgroup.Spec.WorkloadSelector {
+		selectorBody := resource.AppendNewBlock("workload_selector", nil).Body()
+		for _, requirement := range selector.Requirements {
+			requirementBody := selectorBody.AppendNewBlock("workload_label_selector", nil).Body()
+			requirementBody.SetAttributeValue("workload_label_key", cty.StringVal(requirement.Key))
+			requirementBody.SetAttributeValue("operator", cty.StringVal(requirement.Operator))
+			setStringList(requirementBody, "values", requirement.Values)
+		}
+	}
+	x.setRefList(resource, "ip_collections", "ipcollection", workloadgroup.Spec.IpCollections)
+
+	return nil
+}
+
+func renderApp(x *exporter, raw json.RawMessage, address string, body *hclwrite.Body) error {
+	app := &App{}
+	if err := json.Unmarshal(raw, app); err != nil {
+		return err
+	}
+
+	resource := body.AppendNewBlock("resource", []string{"psm_app", address}).Body()
+	resource.SetAttributeValue("display_name", cty.StringVal(defaultString(app.Meta.DisplayName, app.Meta.Name)))
+
+	spec := resource.AppendNewBlock("spec", nil).Body()
+	for _, pp := range app.Spec.ProtoPorts {
+		ppBody := spec.AppendNewBlock("proto_ports", nil).Body()
+		ppBody.SetAttributeValue("protocol", cty.StringVal(pp.Protocol))
+		ppBody.SetAttributeValue("ports", cty.StringVal(pp.Ports))
+	}
+	x.setRefList(spec, "apps", "app", app.Spec.Apps)
+	if app.Spec.Timeout != nil {
+		setStringIfNotEmpty(spec, "timeout", *app.Spec.Timeout)
+	}
+
+	if alg := app.Spec.ALG; alg != nil && alg.Type != "" {
+		algBody := spec.AppendNewBlock("alg", nil).Body()
+		algBody.SetAttributeValue("type", cty.StringVal(alg.Type))
+		// psm_app only accepts the nested block matching the ALG type
+		if alg.Type == "icmp" && alg.ICMP != nil {
+			icmp := algBody.AppendNewBlock("icmp", nil).Body()
+			icmp.SetAttributeValue("type", cty.StringVal(alg.ICMP.Type))
+			icmp.SetAttributeValue("code", cty.StringVal(alg.ICMP.Code))
+		}
+		if alg.Type == "dns" && alg.DNS != nil {
+			dns := algBody.AppendNewBlock("dns", nil).Body()
+			dns.SetAttributeValue("drop_multi_question_packets", cty.BoolVal(alg.DNS.DropMultiQuestionPackets))
+			dns.SetAttributeValue("drop_large_domain_name_packets", cty.BoolVal(alg.DNS.DropLargeDomainNamePackets))
+			dns.SetAttributeValue("drop_long_label_packets", cty.BoolVal(alg.DNS.DropLongLabelPackets))
+			maxMessageLength := alg.DNS.MaxMessageLength
+			if maxMessageLength == 0 {
+				maxMessageLength = defaultDNSMessageLength
+			}
+			dns.SetAttributeValue("max_message_length", cty.NumberIntVal(maxMessageLength))
+		}
+		if alg.Type == "ftp" && alg.FTP != nil {
+			ftp := algBody.AppendNewBlock("ftp", nil).Body()
+			ftp.SetAttributeValue("allow_mismatch_ip_address", cty.BoolVal(alg.FTP.AllowMismatchIPAddress))
+		}
+		if alg.Type == "sunrpc" {
+			for _, sunrpc := range alg.SunRPC {
+				algBody.AppendNewBlock("sunrpc", nil).Body().SetAttributeValue("program_id", cty.StringVal(sunrpc.ProgramID))
+			}
+		}
+		if alg.Type == "msrpc" {
+			for _, msrpc := range alg.MSRPC {
+				algBody.AppendNewBlock("msrpc", nil).Body().SetAttributeValue("program_uuid", cty.StringVal(msrpc.ProgramUUID))
+			}
+		}
+		if alg.Type == "tftp" {
+			algBody.AppendNewBlock("tftp", nil)
+		}
+		if alg.Type == "rtsp" {
+			algBody.AppendNewBlock("rtsp", nil)
+		}
+	}
+
+	return nil
+}
+
+func renderNATPolicy(x *exporter, raw json.RawMessage, address string, body *hclwrite.Body) error {
+	natPolicy := &NATPolicy{}
+	if err := json.Unmarshal(raw, natPolicy); err != nil {
+		return err
+	}
+
+	resource := body.AppendNewBlock("resource", []string{"psm_nat_policy", address}).Body()
+	resource.SetAttributeValue("display_name", cty.StringVal(defaultString(natPolicy.Meta.DisplayName, natPolicy.Meta.Name)))
+	setStringListIfNotEmpty(resource, "policy_distribution_targets", natPolicy.Spec.PolicyDistributionTargets)
+
+	for _, rule := range natPolicy.Spec.Rules {
+		resource.AppendNewline()
+		ruleBody := resource.AppendNewBlock("rule", nil).Body()
+		ruleBody.SetAttributeValue("name", cty.StringVal(rule.Name))
+		if rule.Disable {
+			ruleBody.SetAttributeValue("disable", cty.True)
+		}
+		setStringIfNotEmpty(ruleBody, "type", rule.Type)
+		x.renderAddressCollection(ruleBody, "source", rule.Source)
+		x.renderAddressCollection(ruleBody, "destination", rule.Destination)
+		if rule.DestinationProtoPort.Protocol != "" {
+			ppBody := ruleBody.AppendNewBlock("destination_proto_port", nil).Body()
+			ppBody.SetAttributeValue("protocol", cty.StringVal(rule.DestinationProtoPort.Protocol))
+			setStringIfNotEmpty(ppBody, "ports", rule.DestinationProtoPort.Ports)
+		}
+		x.renderAddressCollection(ruleBody, "translated_source", rule.TranslatedSource)
+		x.renderAddressCollection(ruleBody, "translated_destination", rule.TranslatedDestination)
+		setStringIfNotEmpty(ruleBody, "translated_destination_port", rule.TranslatedDestinationPort)
+	}
+
+	return nil
+}
+
+func (x *exporter) renderAddressCollection(body *hclwrite.Body, name string, collection *AddressCollection) {
+	if collection == nil || (len(collection.Addresses) == 0 && len(collection.IPCollections) == 0) {
+		return
+	}
+	collectionBody := body.AppendNewBlock(name, nil).Body()
+	setStringListIfNotEmpty(collectionBody, "addresses", collection.Addresses)
+	x.setRefList(collectionBody, "ipcollections", "ipcollection", collection.IPCollections)
+}
+
+func renderIPSecPolicy(x *exporter, raw json.RawMessage, address string, body *hclwrite.Body) error {
+	tunnel := &Tunnel{}
+	if err := json.Unmarshal(raw, tunnel); err != nil {
+		return err
+	}
+
+	resource := body.AppendNewBlock("resource", []string{"psm_ipsec_policy", address}).Body()
+	resource.SetAttributeValue("display_name", cty.StringVal(defaultString(tunnel.Meta.DisplayName, tunnel.Meta.Name)))
+
+	tunnelBody := resource.AppendNewBlock("tunnel", nil).Body()
+	tunnelBody.SetAttributeValue("ha_mode", cty.StringVal(tunnel.Spec.HAMode))
+	setStringList(tunnelBody, "policy_distribution_targets", tunnel.Spec.PolicyDistributionTargets)
+	if tunnel.Spec.DisableTCPMSSAdjust {
+		tunnelBody.SetAttributeValue("disable_tcp_mss_adjust", cty.True)
+	}
+
+	for _, endpoint := range tunnel.Spec.TunnelEndpoints {
+		endpointBody := tunnelBody.AppendNewBlock("tunnel_endpoints", nil).Body()
+		endpointBody.SetAttributeValue("interface_name", cty.StringVal(endpoint.InterfaceName))
+		endpointBody.SetAttributeValue("dse", cty.StringVal(endpoint.DSE))
+		endpointBody.SetAttributeValue("ike_version", cty.StringVal(endpoint.IKEVersion))
+
+		if endpoint.IKESA != nil {
+			ikesa := endpointBody.AppendNewBlock("ike_sa", nil).Body()
+			setStringList(ikesa, "encryption_algorithms", endpoint.IKESA.EncryptionAlgorithms)
+			setStringList(ikesa, "hash_algorithms", endpoint.IKESA.HashAlgorithms)
+			setStringList(ikesa, "dh_groups", endpoint.IKESA.DHGroups)
+			ikesa.SetAttributeValue("rekey_lifetime", cty.StringVal(endpoint.IKESA.RekeyLifetime))
+			ikesa.SetAttributeValue("reauth_lifetime", cty.StringVal(endpoint.IKESA.ReauthLifetime))
+			ikesa.SetAttributeValue("dpd_delay", cty.StringVal(endpoint.IKESA.DPDDelay))
+			ikesa.SetAttributeValue("ikev1_dpd_timeout", cty.StringVal(endpoint.IKESA.IKEV1DPDTimeout))
+			ikesa.SetAttributeValue("ike_initiator", cty.BoolVal(endpoint.IKESA.IKEInitiator))
+			ikesa.SetAttributeValue("auth_type", cty.StringVal(endpoint.IKESA.AuthType))
+			setStringIfNotEmpty(ikesa, "local_identity_certificates", endpoint.IKESA.LocalIdentityCertificates)
+			setStringListIfNotEmpty(ikesa, "remote_ca_certificates", endpoint.IKESA.RemoteCACertificates)
+		}
+
+		if endpoint.IPSECSA != nil {
+			ipsecsa := endpointBody.AppendNewBlock("ipsec_sa", nil).Body()
+			setStringList(ipsecsa, "encryption_algorithms", endpoint.IPSECSA.EncryptionAlgorithms)
+			setStringList(ipsecsa, "dh_groups", endpoint.IPSECSA.DHGroups)
+			ipsecsa.SetAttributeValue("rekey_lifetime", cty.StringVal(endpoint.IPSECSA.RekeyLifetime))
+		}
+
+		localID := endpointBody.AppendNewBlock("local_identifier", nil).Body()
+		localID.SetAttributeValue("type", cty.StringVal(endpoint.LocalIdentifier.Type))
+		localID.SetAttributeValue("value", cty.StringVal(endpoint.LocalIdentifier.Value))
+
+		remoteID := endpointBody.AppendNewBlock("remote_identifier", nil).Body()
+		remoteID.SetAttributeValue("type", cty.StringVal(endpoint.RemoteIdentifier.Type))
+		remoteID.SetAttributeValue("value", cty.StringVal(endpoint.RemoteIdentifier.Value))
+
+		if endpoint.Lifetime != nil {
+			lifetime := endpointBody.AppendNewBlock("lifetime", nil).Body()
+			setStringIfNotEmpty(lifetime, "sa_lifetime", endpoint.Lifetime.SALifetime)
+			setStringIfNotEmpty(lifetime, "ike_lifetime", endpoint.Lifetime.IKELifetime)
+		}
+	}
+
+	return nil
+}
+
+func renderMirrorSession(x *exporter, raw json.RawMessage, address string, body *hclwrite.Body) error {
+	session := &MirrorSession{}
+	if err := json.Unmarshal(raw, session); err != nil {
+		return err
+	}
+
+	resource := body.AppendNewBlock("resource", []string{"psm_mirror_session", address}).Body()
+	resource.SetAttributeValue("name", cty.StringVal(session.Meta.Name))
+	resource.SetAttributeValue("span_id", cty.NumberIntVal(int64(session.Spec.SpanID)))
+	resource.SetAttributeValue("packet_size", cty.NumberIntVal(int64(session.Spec.PacketSize)))
+	if session.Spec.Disabled {
+		resource.SetAttributeValue("disabled", cty.True)
+	}
+	if len(session.Spec.PolicyDistributionTargets) > 0 && session.Spec.PolicyDistributionTargets[0] != "default" {
+		resource.SetAttributeValue("policy_distribution_target", cty.StringVal(session.Spec.PolicyDistributionTargets[0]))
+	}
+
+	for _, collector := range session.Spec.Collectors {
+		collectorBody := resource.AppendNewBlock("collector", nil).Body()
+		collectorBody.SetAttributeValue("type", cty.StringVal(collector.Type))
+		collectorBody.SetAttributeValue("destination", cty.StringVal(collector.ExportConfig.Destination))
+		x.setRef(collectorBody, "virtual_router", "virtualrouter", collector.ExportConfig.VirtualRouter)
+	}
+
+	return nil
+}
+
+func renderSyslogPolicy(x *exporter, raw json.RawMessage, address string, body *hclwrite.Body) error {
+	policy := &SyslogPolicy{}
+	if err := json.Unmarshal(raw, policy); err != nil {
+		return err
+	}
+
+	resource := body.AppendNewBlock("resource", []string{"psm_syslog_export_policy", address}).Body()
+	resource.SetAttributeValue("name", cty.StringVal(policy.Meta.Name))
+	resource.SetAttributeValue("format", cty.StringVal(policy.Spec.Format))
+	setStringList(resource, "filter", policy.Spec.Filter)
+
+	syslogConfig := resource.AppendNewBlock("syslogconfig", nil).Body()
+	syslogConfig.SetAttributeValue("facility", cty.StringVal(policy.Spec.Config.FacilityOverride))
+	syslogConfig.SetAttributeValue("disable_batching", cty.BoolVal(policy.Spec.Config.DisableBatching))
+
+	resource.AppendNewBlock("psm_target", nil).Body().SetAttributeValue("enable", cty.BoolVal(policy.Spec.PsmTarget.Enable))
+
+	for _, target := range policy.Spec.Targets {
+		targetBody := resource.AppendNewBlock("targets", nil).Body()
+		targetBody.SetAttributeValue("destination", cty.StringVal(target.Destination))
+		targetBody.SetAttributeValue("transport", cty.StringVal(target.Transport))
+		setStringIfNotEmpty(targetBody, "trusted_certs", target.TrustedCerts)
+		setStringIfNotEmpty(targetBody, "client_certificate", target.ClientCertificate)
+		if opt := target.ServerCertificateVerificationOpt; opt != nil {
+			setStringIfNotEmpty(targetBody, "hostname_verification", opt.Hostname)
+			if opt.SkipServerCertVerification != nil && *opt.SkipServerCertVerification {
+				targetBody.SetAttributeValue("skip_cert_verification", cty.True)
+			}
+		}
+	}
+
+	return nil
+}
+
+func renderFlowExportPolicy(x *exporter, raw json.RawMessage, address string, body *hclwrite.Body) error {
+	policy := &FlowExportPolicy{}
+	if err := json.Unmarshal(raw, policy); err != nil {
+		return err
+	}
+
+	resource := body.AppendNewBlock("resource", []string{"psm_flow_export_policy", address}).Body()
+	resource.SetAttributeValue("name", cty.StringVal(policy.Meta.Name))
+	resource.SetAttributeValue("interval", cty.StringVal(policy.Spec.Interval))
+	resource.SetAttributeValue("format", cty.StringVal(policy.Spec.Format))
+
+	for _, export := range policy.Spec.Exports {
+		targetBody := resource.AppendNewBlock("target", nil).Body()
+		targetBody.SetAttributeValue("destination", cty.StringVal(export.Destination))
+		targetBody.SetAttributeValue("transport", cty.StringVal(export.Transport))
+	}
+
+	return nil
+}
+
+func renderUser(x *exporter, raw json.RawMessage, address string, body *hclwrite.Body) error {
+	user := &User{}
+	if err := json.Unmarshal(raw, user); err != nil {
+		return err
+	}
+
+	// PSM never returns passwords, so each user gets a sensitive variable that has to be supplied on apply
+	variable := "psm_user_" + address + "_password"
+	variableBody := body.AppendNewBlock("variable", []string{variable}).Body()
+	variableBody.SetAttributeTraversal("type", hcl.Traversal{hcl.TraverseRoot{Name: "string"}})
+	variableBody.SetAttributeValue("sensitive", cty.True)
+	body.AppendNewline()
+
+	resource := body.AppendNewBlock("resource", []string{"psm_user", address}).Body()
+	resource.SetAttributeValue("name", cty.StringVal(user.Meta.Name))
+	resource.SetAttributeValue("fullname", cty.StringVal(user.Spec.Fullname))
+	resource.SetAttributeValue("email", cty.StringVal(user.Spec.Email))
+	resource.SetAttributeTraversal("password", hcl.Traversal{hcl.TraverseRoot{Name: "var"}, hcl.TraverseAttr{Name: variable}})
+	setStringIfNotEmpty(resource, "type", user.Spec.Type)
+	if user.Meta.Tenant != "" && user.Meta.Tenant != "default" {
+		resource.SetAttributeValue("tenant", cty.StringVal(user.Meta.Tenant))
+	}
+
+	return nil
+}
+
+func renderRole(x *exporter, raw json.RawMessage, address string, body *hclwrite.Body) error {
+	role := &Role{}
+	if err := json.Unmarshal(raw, role); err != nil {
+		return err
+	}
+
+	resource := body.AppendNewBlock("resource", []string{"psm_user_role", address}).Body()
+	resource.SetAttributeValue("name", cty.StringVal(role.Meta.Name))
+	if role.Meta.Namespace != "" && role.Meta.Namespace != "default" {
+		resource.SetAttributeValue("namespace", cty.StringVal(role.Meta.Namespace))
+	}
+	for _, permission := range role.Spec.Permissions {
+		permissionBody := resource.AppendNewBlock("permissions", nil).Body()
+		permissionBody.SetAttributeValue("resource_group", cty.StringVal(permission.ResourceGroup))
+		permissionBody.SetAttributeValue("resource_kind", cty.StringVal(permission.ResourceKind))
+		setStringList(permissionBody, "actions", permission.Actions)
+	}
+
+	return nil
+}
+
+func renderRoleBinding(x *exporter, raw json.RawMessage, address string, body *hclwrite.Body) error {
+	roleBinding := &RoleBinding{}
+	if err := json.Unmarshal(raw, roleBinding); err != nil {
+		return err
+	}
+
+	resource := body.AppendNewBlock("resource", []string{"psm_role_binding", address}).Body()
+	resource.SetAttributeValue("name", cty.StringVal(roleBinding.Meta.Name))
+	if roleBinding.Meta.Tenant != "" && roleBinding.Meta.Tenant != "default" {
+		resource.SetAttributeValue("tenant", cty.StringVal(roleBinding.Meta.Tenant))
+	}
+	if roleBinding.Meta.Namespace != "" && roleBinding.Meta.Namespace != "default" {
+		resource.SetAttributeValue("namespace", cty.StringVal(roleBinding.Meta.Namespace))
+	}
+	x.setRefList(resource, "users", "user", roleBinding.Spec.Users)
+	setStringListIfNotEmpty(resource, "user_groups", roleBinding.Spec.UserGroups)
+	x.setRef(resource, "role", "role", roleBinding.Spec.Role)
+
+	return nil
+}
+
+// getNetworkSecurityPolicy fetches a security policy by name using the same endpoint as resourceRulesRead.
+func getNetworkSecurityPolicy(ctx context.Context, config *Config, name string) (*NetworkSecurityPolicy, error) {
+	client := config.Client()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", config.Server+"/configs/security/v1/tenant/default/networksecuritypolicies/"+name, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.AddCookie(&http.Cookie{Name: "sid", Value: config.SID})
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to read Security Policy %s: HTTP %d %s: %s", name, resp.StatusCode, resp.Status, bodyBytes)
+	}
+
+	policy := &NetworkSecurityPolicy{}
+	if err := json.NewDecoder(resp.Body).Decode(policy); err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+// appendImportBlock writes a Terraform 1.5+ import block that adopts the PSM object id into resourceType.address.
+func appendImportBlock(body *hclwrite.Body, resourceType, address, id string) {
+	importBody := body.AppendNewBlock("import", nil).Body()
+	importBody.SetAttributeTraversal("to", hcl.Traversal{
+		hcl.TraverseRoot{Name: resourceType},
+		hcl.TraverseAttr{Name: address},
+	})
+	importBody.SetAttributeValue("id", cty.StringVal(id))
+	body.AppendNewline()
+}
+
+func objectMeta(raw json.RawMessage) exportMeta {
+	object := exportObject{}
+	if err := json.Unmarshal(raw, &object); err != nil {
+		log.Printf("[WARN] Unable to decode object metadata: %s", err)
+	}
+	return object.Meta
+}
+
+func importByName(meta exportMeta) string {
+	return meta.Name
+}
+
+func refAttr(name string) hcl.Traversal {
+	return hcl.Traversal{hcl.TraverseAttr{Name: name}}
+}
+
+func defaultString(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+var invalidResourceNameChars = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+
+// terraformResourceName turns a PSM object name into a valid Terraform resource name.
+func terraformResourceName(name string) string {
+	result := invalidResourceNameChars.ReplaceAllString(name, "_")
+	if result == "" || !(result[0] == '_' || (result[0] >= 'a' && result[0] <= 'z') || (result[0] >= 'A' && result[0] <= 'Z')) {
+		result = "_" + result
+	}
+	return result
+}
+
+func setStringIfNotEmpty(body *hclwrite.Body, key, value string) {
+	if value != "" {
+		body.SetAttributeValue(key, cty.StringVal(value))
+	}
+}
+
+func setStringList(body *hclwrite.Body, key string, values []string) {
+	if len(values) == 0 {
+		body.SetAttributeValue(key, cty.ListValEmpty(cty.String))
+		return
+	}
+	setStringListIfNotEmpty(body, key, values)
+}
+
+func setStringListIfNotEmpty(body *hclwrite.Body, key string, values []string) {
+	if len(values) == 0 {
+		return
+	}
+	list := make([]cty.Value, len(values))
+	for i, v := range values {
+		list[i] = cty.StringVal(v)
+	}
+	body.SetAttributeValue(key, cty.ListVal(list))
+}
+
+func setStringMapIfNotEmpty(body *hclwrite.Body, key string, values map[string]string) {
+	if len(values) == 0 {
+		return
+	}
+	m := make(map[string]cty.Value, len(values))
+	for k, v := range values {
+		m[k] = cty.StringVal(v)
+	}
+	body.SetAttributeValue(key, cty.MapVal(m))
+}
@@ -0,0 +1,365 @@
+package psm
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+func TestTerraformResourceName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"web", "web"},
+		{"web-tier_01", "web-tier_01"},
+		{"web tier.v2", "web_tier_v2"},
+		{"10.0.0.0/8", "_10_0_0_0_8"},
+		{"-leading-dash", "_-leading-dash"},
+		{"", "_"},
+	}
+
+	for _, tt := range tests {
+		if got := terraformResourceName(tt.name); got != tt.want {
+			t.Errorf("terraformResourceName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestExportSecurityPolicy(t *testing.T) {
+	config := newTestServer(t, map[string]string{
+		"/configs/security/v1/tenant/default/networksecuritypolicies/allow-web": `{
+			"meta": {"name": "allow-web", "tenant": "default"},
+			"spec": {
+				"policy-distribution-targets": ["default"],
+				"rules": [
+					{
+						"name": "web",
+						"action": "permit",
+						"from-ip-addresses": ["any"],
+						"to-ip-addresses": ["10.0.0.0/24"],
+						"proto-ports": [{"protocol": "tcp", "ports": "443"}]
+					},
+					{"action": "deny", "disable": true, "from-ip-addresses": ["any"], "to-ip-addresses": ["any"]}
+				]
+			}
+		}`,
+	})
+
+	var out bytes.Buffer
+	if err := Export(context.Background(), config, "networksecuritypolicy", "allow-web", &out); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	want := `import {
+  to = psm_rules.allow-web
+  id = "allow-web"
+}
+
+resource "psm_rules" "allow-web" {
+  policy_name = "allow-web"
+
+  rule {
+    rule_name         = "web"
+    action            = "permit"
+    from_ip_addresses = ["any"]
+    to_ip_addresses   = ["10.0.0.0/24"]
+    proto_ports {
+      protocol = "tcp"
+      ports    = "443"
+    }
+  }
+
+  rule {
+    action            = "deny"
+    from_ip_addresses = ["any"]
+    to_ip_addresses   = ["any"]
+    disable           = true
+  }
+}
+`
+	if got := strings.TrimSpace(out.String()); got != strings.TrimSpace(want) {
+		t.Errorf("Export() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestExportUnsupportedKind(t *testing.T) {
+	err := Export(context.Background(), &Config{}, "nosuchkind", "x", &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "unsupported kind") {
+		t.Errorf("Export() error = %v, want unsupported kind", err)
+	}
+}
+
+func TestExportNotFound(t *testing.T) {
+	config := newTestServer(t, nil)
+
+	err := Export(context.Background(), config, "networksecuritypolicy", "missing", &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "HTTP 404") {
+		t.Errorf("Export() error = %v, want HTTP 404", err)
+	}
+}
+
+// TestExportKindsMatchProvider checks that every exported kind renders a resource the provider serves, and that
+// references to it use an attribute that resource has.
+func TestExportKindsMatchProvider(t *testing.T) {
+	resources := Provider().ResourcesMap
+
+	for _, k := range exportKinds {
+		resource, ok := resources[k.resourceType]
+		if !ok {
+			t.Errorf("%s: resource %s is not registered by the provider", k.kind, k.resourceType)
+			continue
+		}
+		attr := k.refAttr[0].(hcl.TraverseAttr).Name
+		if attr == "id" {
+			continue
+		}
+		if _, ok := resource.Schema[attr]; !ok {
+			t.Errorf("%s: resource %s has no attribute %q to reference it by", k.kind, k.resourceType, attr)
+		}
+	}
+}
+
+func TestExporterAssignAddress(t *testing.T) {
+	x := newExporter(&Config{})
+	k := findExportKind("ipcollection")
+
+	tests := []struct {
+		meta exportMeta
+		want string
+	}{
+		{exportMeta{Name: "web"}, "web"},
+		{exportMeta{Name: "3f2a", DisplayName: "web"}, "web_2"},
+		{exportMeta{Name: "web"}, "web"},
+		{exportMeta{Name: "db.internal"}, "db_internal"},
+		{exportMeta{Name: "db_internal"}, "db_internal_2"},
+	}
+
+	for _, tt := range tests {
+		if got := x.assignAddress(k, tt.meta); got != tt.want {
+			t.Errorf("assignAddress(%+v) = %q, want %q", tt.meta, got, tt.want)
+		}
+	}
+}
+
+func TestExportAllResolvesReferences(t *testing.T) {
+	config := newTestServer(t, map[string]string{
+		"/configs/network/v1/tenant/default/virtualrouters": `{"items": [{
+			"meta": {"name": "blue"},
+			"spec": {"ingress-nat-policy": ["nat-7c1e"], "ipsec-policy": ["vpn-01"], "egress-nat-policy": ["unmanaged"]}
+		}]}`,
+		"/configs/network/v1/tenant/default/natpolicies": `{"items": [{
+			"meta": {"name": "nat-7c1e", "display-name": "outbound"},
+			"spec": {"rules": [{"name": "snat", "type": "static", "source": {"ipcollections": ["ipc-1"]}}]}
+		}]}`,
+		"/configs/security/v1/tenant/default/ipsecpolicies": `{"items": [{"meta": {"name": "vpn-01"}}]}`,
+		"/configs/network/v1/tenant/default/ipcollections": `{"items": [
+			{"meta": {"name": "ipc-1", "display-name": "internal"}, "spec": {"addresses": ["10.0.0.0/8"], "ipcollections": ["ipc-2"]}},
+			{"meta": {"name": "ipc-2", "display-name": "lab"}, "spec": {"addresses": ["192.168.0.0/16"]}}
+		]}`,
+	})
+
+	dir := t.TempDir()
+	if err := ExportAll(context.Background(), config, dir); err == nil {
+		t.Fatal("ExportAll() error = nil, want errors for the kinds the fake PSM does not list")
+	}
+
+	tests := []struct {
+		file string
+		want []string
+	}{
+		{"psm_vrf.tf", []string{
+			`ingress_nat_policy = [psm_nat_policy.outbound.name]`,
+			`egress_nat_policy  = ["unmanaged"]`,
+			`ipsec_policy       = [psm_ipsec_policy.vpn-01.name]`,
+		}},
+		{"psm_nat_policy.tf", []string{
+			`to = psm_nat_policy.outbound`,
+			`ipcollections = [psm_ipcollection.internal.name]`,
+		}},
+		{"psm_ipcollection.tf", []string{
+			`id = "ipc-1"`,
+			`ip_collections = [psm_ipcollection.lab.name]`,
+		}},
+	}
+
+	for _, tt := range tests {
+		content, err := os.ReadFile(filepath.Join(dir, tt.file))
+		if err != nil {
+			t.Errorf("%s was not written: %v", tt.file, err)
+			continue
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(string(content), want) {
+				t.Errorf("%s does not contain %q:\n%s", tt.file, want, content)
+			}
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "psm_network.tf")); !os.IsNotExist(err) {
+		t.Errorf("psm_network.tf was written although no network was listed")
+	}
+}
+
+func TestExportRoutedNetwork(t *testing.T) {
+	config := newTestServer(t, map[string]string{
+		"/configs/network/v1/tenant/default/networks/routed": `{
+			"meta": {"name": "routed", "tenant": "default"},
+			"spec": {
+				"type": "routed",
+				"ipv4-subnet": "10.10.0.0/24",
+				"ipv4-gateway": "10.10.0.1",
+				"vxlan-vni": 10010,
+				"virtual-router": "blue",
+				"route-import-export": {
+					"address-family": "l2vpn-evpn",
+					"rd-auto": true,
+					"export-rts": [{"type": "type2", "admin-value": 65000, "assigned-value": 10010}]
+				}
+			}
+		}`,
+	})
+
+	var out bytes.Buffer
+	if err := Export(context.Background(), config, "network", "routed", &out); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	for _, want := range []string{
+		`type           = "routed"`,
+		`ipv4_subnet    = "10.10.0.0/24"`,
+		`ipv4_gateway   = "10.10.0.1"`,
+		`vxlan_vni      = 10010`,
+		`virtual_router = "blue"`,
+		"route_import_export {",
+		`admin_value    = 65000`,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Export() does not contain %q:\n%s", want, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "vlan_id") {
+		t.Errorf("Export() sets vlan_id on a routed network:\n%s", out.String())
+	}
+}
+
+func TestExportVRFRouting(t *testing.T) {
+	config := newTestServer(t, map[string]string{
+		"/configs/network/v1/tenant/default/virtualrouters/blue": `{
+			"meta": {"name": "blue", "tenant": "default"},
+			"spec": {
+				"static-routes": [
+					{"prefix": "0.0.0.0/0", "next-hop": "10.0.0.1"},
+					{"prefix": "192.168.0.0/16", "next-hop": "10.0.0.2", "admin-distance": 20}
+				]
+			}
+		}`,
+		"/configs/network/v1/routingconfigs/default": `{
+			"meta": {"name": "default"},
+			"spec": {"bgp-config": {
+				"router-id": "10.0.0.1",
+				"as-number": {"as-dot-notation": "65001"},
+				"keepalive-interval": 60,
+				"holdtime": 180,
+				"neighbors": [{
+					"ip-address": "10.0.0.2",
+					"remote-as": {"as-dot-notation": "65002"},
+					"multi-hop": 1,
+					"enable-address-families": ["ipv4-unicast"],
+					"keepalive-interval": 60,
+					"holdtime": 180,
+					"shutdown": true
+				}]
+			}}
+		}`,
+	})
+
+	tests := []struct {
+		kind, name string
+		want       []string
+		wantNot    []string
+	}{
+		{
+			kind: "virtualrouter",
+			name: "blue",
+			want: []string{
+				"static_route {\n    prefix   = \"0.0.0.0/0\"\n    next_hop = \"10.0.0.1\"\n  }",
+				`admin_distance = 20`,
+			},
+		},
+		{
+			kind: "routingconfig",
+			name: "default",
+			want: []string{
+				`resource "psm_bgp_config" "default"`,
+				`router_id          = "10.0.0.1"`,
+				`as_number          = "65001"`,
+				`remote_as          = "65002"`,
+				`address_families   = ["ipv4-unicast"]`,
+				`shutdown           = true`,
+			},
+			wantNot: []string{"password"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := Export(context.Background(), config, tt.kind, tt.name, &out); err != nil {
+				t.Fatalf("Export() error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("Export() does not contain %q:\n%s", want, out.String())
+				}
+			}
+			for _, unwanted := range tt.wantNot {
+				if strings.Contains(out.String(), unwanted) {
+					t.Errorf("Export() contains %q:\n%s", unwanted, out.String())
+				}
+			}
+		})
+	}
+}
+
+func TestExportIPAMPolicy(t *testing.T) {
+	config := newTestServer(t, map[string]string{
+		"/configs/network/v1/tenant/default/ipam-policies/relay": `{
+			"meta": {"name": "relay"},
+			"spec": {"type": "dhcp-relay", "dhcp-relay": {"servers": [
+				{"ip-address": "10.0.0.53", "virtual-router": "default"},
+				{"ip-address": "10.1.0.53", "virtual-router": "blue"}
+			]}}
+		}`,
+	})
+
+	var out bytes.Buffer
+	if err := Export(context.Background(), config, "ipampolicy", "relay", &out); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	want := `import {
+  to = psm_ipam_policy.relay
+  id = "relay"
+}
+
+resource "psm_ipam_policy" "relay" {
+  name = "relay"
+  dhcp_server {
+    ip_address = "10.0.0.53"
+  }
+  dhcp_server {
+    ip_address     = "10.1.0.53"
+    virtual_router = "blue"
+  }
+}
+`
+	if got := strings.TrimSpace(out.String()); got != strings.TrimSpace(want) {
+		t.Errorf("Export() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,74 @@
+package psm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	ctyjson "github.com/hashicorp/go-cty/cty/json"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// newTestServer starts a fake PSM that answers GET requests for the given paths with the given JSON bodies and
+// returns 404 for anything else. The returned Config points at it.
+func newTestServer(t *testing.T, responses map[string]string) *Config {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := responses[r.URL.Path]
+		if !ok || r.Method != http.MethodGet {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	return &Config{Server: server.URL}
+}
+
+// testPlan plans config against the given state attributes, or for a new resource if state is nil, the way
+// Terraform does during a plan. The raw configuration is filled in as well, as some CustomizeDiff functions use it
+// to tell unset attributes apart. Errors from validating the plan are returned.
+func testPlan(t *testing.T, r *schema.Resource, state map[string]string, config map[string]interface{}, meta interface{}) (*terraform.InstanceDiff, error) {
+	t.Helper()
+
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rawConfig, err := ctyjson.Unmarshal(configJSON, r.CoreConfigSchema().ImpliedType())
+	if err != nil {
+		t.Fatalf("invalid test configuration: %v", err)
+	}
+
+	var instanceState *terraform.InstanceState
+	if state != nil {
+		instanceState = &terraform.InstanceState{ID: "test", Attributes: state}
+	} else {
+		instanceState = &terraform.InstanceState{}
+	}
+	instanceState.RawConfig = rawConfig
+
+	return r.Diff(context.Background(), instanceState, terraform.NewResourceConfigRaw(config), meta)
+}
+
+// plannedData returns the resource data as planned by diff, for reading planned values with Get.
+func plannedData(t *testing.T, r *schema.Resource, state map[string]string, diff *terraform.InstanceDiff) *schema.ResourceData {
+	t.Helper()
+
+	d, err := schema.InternalMap(r.Schema).Data(&terraform.InstanceState{ID: "test", Attributes: state}, diff)
+	if err != nil {
+		t.Fatalf("Data() error = %v", err)
+	}
+	return d
+}
+
+// hashString is the hash a set of strings uses as flatmap key for an element in state.
+func hashString(v string) int {
+	return schema.HashSchema(&schema.Schema{Type: schema.TypeString})(v)
+}
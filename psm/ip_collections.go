@@ -1,13 +1,21 @@
 package psm
 
 import (
+	"bufio"
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"net/netip"
+	"os"
+	"slices"
+	"sort"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -24,6 +32,7 @@ func resourceIPCollection() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		CustomizeDiff: resourceIPCollectionCustomizeDiff,
 		Schema: map[string]*schema.Schema{
 			"display_name": {
 				Type:     schema.TypeString,
@@ -39,9 +48,28 @@ func resourceIPCollection() *schema.Resource {
 				Default:  "default",
 			},
 			"addresses": {
-				Type:     schema.TypeList,
+				Type:     schema.TypeSet,
 				Optional: true,
-				Elem:     &schema.Schema{Type: schema.TypeString},
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validateIPCollectionAddress,
+				},
+			},
+			"addresses_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Path of a file with one address, CIDR block or range per line, merged with addresses",
+			},
+			"addresses_file_hash": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "SHA-256 of the addresses sent to PSM when addresses_file is set",
+			},
+			"aggregate_addresses": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Merge overlapping and adjacent CIDR blocks before sending them to PSM",
 			},
 			"ip_collections": {
 				Type:     schema.TypeList,
@@ -57,6 +85,7 @@ func resourceIPCollection() *schema.Resource {
 					"IPv6",
 				}, false),
 			},
+			"force_detach": forceDetachSchema(),
 		},
 	}
 }
@@ -87,11 +116,11 @@ func resourceIPCollectionCreate(ctx context.Context, d *schema.ResourceData, m i
 	ipCollection.Meta.Tenant = d.Get("tenant").(string)
 	ipCollection.Spec.AddressFamily = d.Get("address_family").(string)
 
-	if addresses, ok := d.GetOk("addresses"); ok {
-		for _, addr := range addresses.([]interface{}) {
-			ipCollection.Spec.Addresses = append(ipCollection.Spec.Addresses, addr.(string))
-		}
+	addresses, fileHash, err := expandIPCollectionAddresses(d)
+	if err != nil {
+		return diag.FromErr(err)
 	}
+	ipCollection.Spec.Addresses = addresses
 	if ipcollections, ok := d.GetOk("ip_collections"); ok {
 		for _, coll := range ipcollections.([]interface{}) {
 			ipCollection.Spec.IPCollections = append(ipCollection.Spec.IPCollections, coll.(string))
@@ -102,6 +131,7 @@ func resourceIPCollectionCreate(ctx context.Context, d *schema.ResourceData, m i
 	if err != nil {
 		return diag.FromErr(err)
 	}
+	log.Printf("[DEBUG] Creating ip_collection %s with %d addresses", ipCollection.Meta.DisplayName, len(addresses))
 
 	req, err := http.NewRequestWithContext(ctx, "POST", config.Server+"/configs/network/v1/tenant/default/ipcollections", bytes.NewBuffer(jsonBytes))
 	if err != nil {
@@ -126,12 +156,8 @@ func resourceIPCollectionCreate(ctx context.Context, d *schema.ResourceData, m i
 		return diag.FromErr(err)
 	}
 
-	// Log the request details
-	log.Printf("[DEBUG] Request method: %s", req.Method)
-	log.Printf("[DEBUG] Request URL: %s", req.URL.String())
-	log.Printf("[DEBUG] Request body: %s", jsonBytes)
-
 	d.SetId(responseIPCollection.Meta.UUID)
+	d.Set("addresses_file_hash", fileHash)
 	d.Set("name", responseIPCollection.Meta.Name)
 	d.Set("address_family", responseIPCollection.Spec.AddressFamily)
 
@@ -174,7 +200,9 @@ func resourceIPCollectionRead(ctx context.Context, d *schema.ResourceData, m int
 	d.Set("display_name", ipCollection.Meta.DisplayName)
 	d.Set("name", ipCollection.Meta.Name)
 	d.Set("tenant", ipCollection.Meta.Tenant)
-	d.Set("addresses", ipCollection.Spec.Addresses)
+	if err := flattenIPCollectionAddresses(d, ipCollection.Spec.Addresses); err != nil {
+		return diag.FromErr(err)
+	}
 	d.Set("ip_collections", ipCollection.Spec.IPCollections)
 	d.Set("address_family", ipCollection.Spec.AddressFamily)
 
@@ -191,11 +219,19 @@ func resourceIPCollectionUpdate(ctx context.Context, d *schema.ResourceData, m i
 	ipCollection.Meta.Tenant = d.Get("tenant").(string)
 	ipCollection.Spec.AddressFamily = d.Get("address_family").(string)
 
-	addresses := d.Get("addresses").([]interface{})
-	ipCollection.Spec.Addresses = make([]string, len(addresses))
-	for i, addr := range addresses {
-		ipCollection.Spec.Addresses[i] = addr.(string)
+	addresses, fileHash, err := expandIPCollectionAddresses(d)
+	if err != nil {
+		return diag.FromErr(err)
 	}
+	ipCollection.Spec.Addresses = addresses
+	d.Set("addresses_file_hash", fileHash)
+
+	// The ipcollections API has no way to add or remove individual addresses, a PUT replaces the whole collection.
+	// Sending only the changes, or splitting a large change over several requests, is therefore not possible and
+	// every update sends the complete normalized list in a single request.
+	oldAddresses, newAddresses := d.GetChange("addresses")
+	log.Printf("[DEBUG] Updating ip_collection %s with %d addresses (%d configured addresses added, %d removed)", d.Id(), len(addresses),
+		newAddresses.(*schema.Set).Difference(oldAddresses.(*schema.Set)).Len(), oldAddresses.(*schema.Set).Difference(newAddresses.(*schema.Set)).Len())
 
 	ipCollections := d.Get("ip_collections").([]interface{})
 	ipCollection.Spec.IPCollections = make([]string, len(ipCollections))
@@ -234,6 +270,10 @@ func resourceIPCollectionDelete(ctx context.Context, d *schema.ResourceData, m i
 	config := m.(*Config)
 	client := config.Client()
 
+	if diags := checkPolicyReferences(ctx, d, config, referenceIPCollection, d.Get("name").(string)); diags.HasError() {
+		return diags
+	}
+
 	url := fmt.Sprintf("%s/configs/network/v1/tenant/default/ipcollections/%s", config.Server, d.Id())
 
 	req, err := http.NewRequestWithContext(ctx, "DELETE", url, nil)
@@ -253,7 +293,358 @@ func resourceIPCollectionDelete(ctx context.Context, d *schema.ResourceData, m i
 		return diag.Errorf("failed to delete ip_collection: HTTP %s", resp.Status)
 	}
 
+	// A deleted collection no longer nests anything
+	for _, key := range []string{"name", "display_name"} {
+		config.plannedIPCollections.Delete(d.Get(key).(string))
+	}
+
 	d.SetId("")
 
 	return nil
 }
+
+// resourceIPCollectionCustomizeDiff rejects nesting cycles and rehashes addresses_file on every plan so that editing
+// the file, rather than the configuration, produces an update.
+func resourceIPCollectionCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	// Collections are referred to by name, which is only known once created, or by display name
+	var names []string
+	for _, key := range []string{"name", "display_name"} {
+		if name := d.Get(key).(string); d.NewValueKnown(key) && name != "" && !containsString(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	if config, ok := m.(*Config); ok {
+		// Drop what was recorded under names the collection no longer goes by, or for nested collections that are
+		// no longer known, so that an earlier plan cannot report a cycle that does not exist anymore
+		for _, key := range []string{"name", "display_name"} {
+			if old, _ := d.GetChange(key); old.(string) != "" && !containsString(names, old.(string)) {
+				config.plannedIPCollections.Delete(old.(string))
+			}
+		}
+		if !d.NewValueKnown("ip_collections") {
+			for _, name := range names {
+				config.plannedIPCollections.Delete(name)
+			}
+		} else {
+			// Recorded before checking, so that of two collections planned at the same time at least the second
+			// one to be checked sees the other
+			children := expandStringList(d.Get("ip_collections").([]interface{}))
+			for _, name := range names {
+				config.plannedIPCollections.Store(name, children)
+			}
+			if err := checkIPCollectionNesting(ctx, config, names, children); err != nil {
+				return err
+			}
+		}
+	}
+
+	if !d.NewValueKnown("addresses_file") || !d.NewValueKnown("addresses") {
+		return d.SetNewComputed("addresses_file_hash")
+	}
+
+	hash := ""
+	if path := d.Get("addresses_file").(string); path != "" {
+		fileAddresses, err := readAddressesFile(path)
+		if err != nil {
+			return err
+		}
+		addresses := append(ExpandStringSet(d.Get("addresses").(*schema.Set)), fileAddresses...)
+		hash = hashAddresses(mergeIPCollectionAddresses(addresses, d.Get("aggregate_addresses").(bool)))
+	}
+	if d.Get("addresses_file_hash").(string) != hash {
+		return d.SetNew("addresses_file_hash", hash)
+	}
+	return nil
+}
+
+// expandIPCollectionAddresses returns the addresses to send to PSM, the union of addresses and addresses_file,
+// together with their hash if addresses_file is set.
+func expandIPCollectionAddresses(d *schema.ResourceData) ([]string, string, error) {
+	addresses := ExpandStringSet(d.Get("addresses").(*schema.Set))
+
+	path := d.Get("addresses_file").(string)
+	if path != "" {
+		fileAddresses, err := readAddressesFile(path)
+		if err != nil {
+			return nil, "", err
+		}
+		addresses = append(addresses, fileAddresses...)
+	}
+
+	addresses = mergeIPCollectionAddresses(addresses, d.Get("aggregate_addresses").(bool))
+	if path == "" {
+		return addresses, "", nil
+	}
+	return addresses, hashAddresses(addresses), nil
+}
+
+// mergeIPCollectionAddresses normalizes addresses, and aggregates them if requested, the way they are sent to PSM.
+func mergeIPCollectionAddresses(addresses []string, aggregate bool) []string {
+	addresses = normalizeAddresses(addresses)
+	if aggregate {
+		addresses = aggregateAddresses(addresses)
+	}
+	return addresses
+}
+
+// flattenIPCollectionAddresses keeps the configured addresses in state as long as PSM holds the same collection
+// once normalized, so that differences in notation or aggregation do not show up as drift. Without addresses_file,
+// addresses is otherwise set to what PSM returned. With addresses_file, the file is only read when planning and
+// applying, so the collection is compared through addresses_file_hash instead and any difference shows up as a
+// change of the hash.
+func flattenIPCollectionAddresses(d *schema.ResourceData, remote []string) error {
+	remote = normalizeAddresses(remote)
+
+	if d.Get("addresses_file").(string) != "" {
+		return d.Set("addresses_file_hash", hashAddresses(remote))
+	}
+
+	expected := mergeIPCollectionAddresses(ExpandStringSet(d.Get("addresses").(*schema.Set)), d.Get("aggregate_addresses").(bool))
+	if slices.Equal(expected, remote) {
+		return nil
+	}
+	d.Set("addresses_file_hash", "")
+	return d.Set("addresses", remote)
+}
+
+// readAddressesFile reads one address per line, ignoring blank lines and # comments.
+func readAddressesFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read addresses_file: %w", err)
+	}
+	defer f.Close()
+
+	var addresses []string
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		address, _, _ := strings.Cut(scanner.Text(), "#")
+		address = strings.TrimSpace(address)
+		if address == "" {
+			continue
+		}
+		if _, errs := validateIPCollectionAddress(address, "address"); len(errs) > 0 {
+			return nil, fmt.Errorf("%s line %d: %v", path, line, errs[0])
+		}
+		addresses = append(addresses, address)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read addresses_file: %w", err)
+	}
+	return addresses, nil
+}
+
+// validateIPCollectionAddress accepts an IP address, a CIDR block or a range such as 10.1.1.1-10.1.1.100.
+func validateIPCollectionAddress(val interface{}, key string) (warns []string, errs []error) {
+	v := val.(string)
+	if _, err := normalizeAddress(v); err != nil {
+		errs = append(errs, fmt.Errorf("%q must be an IP address, CIDR block or range, got: %s", key, v))
+	}
+	return
+}
+
+// normalizeAddress returns the canonical form of an address: CIDR blocks are masked to their network address, host
+// prefixes such as /32 are written as plain addresses and IPv6 addresses are compressed.
+func normalizeAddress(address string) (string, error) {
+	address = strings.TrimSpace(address)
+
+	if from, to, ok := strings.Cut(address, "-"); ok {
+		start, err := netip.ParseAddr(strings.TrimSpace(from))
+		if err != nil {
+			return "", err
+		}
+		end, err := netip.ParseAddr(strings.TrimSpace(to))
+		if err != nil {
+			return "", err
+		}
+		if start.Is4() != end.Is4() || end.Less(start) {
+			return "", fmt.Errorf("invalid range %s", address)
+		}
+		return start.String() + "-" + end.String(), nil
+	}
+
+	if strings.Contains(address, "/") {
+		prefix, err := netip.ParsePrefix(address)
+		if err != nil {
+			return "", err
+		}
+		prefix = prefix.Masked()
+		if prefix.IsSingleIP() {
+			return prefix.Addr().String(), nil
+		}
+		return prefix.String(), nil
+	}
+
+	addr, err := netip.ParseAddr(address)
+	if err != nil {
+		return "", err
+	}
+	return addr.String(), nil
+}
+
+// normalizeAddresses returns the sorted, de-duplicated canonical forms of addresses. Entries that cannot be
+// parsed are kept verbatim so that PSM reports them.
+func normalizeAddresses(addresses []string) []string {
+	seen := make(map[string]bool, len(addresses))
+	result := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		if normalized, err := normalizeAddress(address); err == nil {
+			address = normalized
+		}
+		if !seen[address] {
+			seen[address] = true
+			result = append(result, address)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// aggregateAddresses merges plain addresses and CIDR blocks into the smallest set of covering prefixes. Ranges are
+// kept as they are.
+func aggregateAddresses(addresses []string) []string {
+	var prefixes []netip.Prefix
+	var result []string
+	for _, address := range addresses {
+		if prefix, err := netip.ParsePrefix(address); err == nil {
+			prefixes = append(prefixes, prefix)
+		} else if addr, err := netip.ParseAddr(address); err == nil {
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+		} else {
+			result = append(result, address)
+		}
+	}
+
+	// Sorting by address, then by prefix length, puts every prefix right after the prefix that covers it
+	sort.Slice(prefixes, func(i, j int) bool {
+		if c := prefixes[i].Addr().Compare(prefixes[j].Addr()); c != 0 {
+			return c < 0
+		}
+		return prefixes[i].Bits() < prefixes[j].Bits()
+	})
+
+	// Drop covered prefixes, then merge sibling prefixes until nothing changes
+	var merged []netip.Prefix
+	for _, prefix := range prefixes {
+		if n := len(merged); n > 0 && merged[n-1].Contains(prefix.Addr()) && merged[n-1].Bits() <= prefix.Bits() {
+			continue
+		}
+		merged = append(merged, prefix)
+		for len(merged) > 1 {
+			n := len(merged)
+			a, b := merged[n-2], merged[n-1]
+			if a.Bits() != b.Bits() || a.Bits() == 0 {
+				break
+			}
+			parent, _ := a.Addr().Prefix(a.Bits() - 1)
+			if parent.Addr() != a.Addr() || !parent.Contains(b.Addr()) {
+				break
+			}
+			merged = append(merged[:n-2], parent)
+		}
+	}
+
+	for _, prefix := range merged {
+		if prefix.IsSingleIP() {
+			result = append(result, prefix.Addr().String())
+		} else {
+			result = append(result, prefix.String())
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+func hashAddresses(addresses []string) string {
+	if len(addresses) == 0 {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(strings.Join(addresses, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// checkIPCollectionNesting walks the collections nested below a collection known by names, using the ip_collections
+// planned for other collections of the configuration where known and PSM otherwise, and fails on cycles. Planned
+// collections are found by name and by display name, so cycles between collections created in the same apply are
+// found as well.
+func checkIPCollectionNesting(ctx context.Context, config *Config, names []string, children []string) error {
+	root := "(new collection)"
+	if len(names) > 0 {
+		root = names[len(names)-1]
+	}
+
+	cache := make(map[string][]string)
+	lookup := func(name string) ([]string, error) {
+		if planned, ok := config.plannedIPCollections.Load(name); ok {
+			return planned.([]string), nil
+		}
+		if nested, ok := cache[name]; ok {
+			return nested, nil
+		}
+		collection, err := getIPCollection(ctx, config, name)
+		if err != nil {
+			return nil, err
+		}
+		var nested []string
+		if collection != nil {
+			nested = collection.Spec.IPCollections
+		}
+		cache[name] = nested
+		return nested, nil
+	}
+
+	var walk func(path []string, children []string) error
+	walk = func(path []string, children []string) error {
+		for _, child := range children {
+			if containsString(names, child) {
+				return fmt.Errorf("ip_collections form a cycle: %s", strings.Join(append(slices.Clone(path), child), " -> "))
+			}
+			if slices.Contains(path, child) {
+				// A cycle not involving this collection, reported when the collections forming it are planned
+				continue
+			}
+			nested, err := lookup(child)
+			if err != nil {
+				return err
+			}
+			if err := walk(append(slices.Clone(path), child), nested); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return walk([]string{root}, children)
+}
+
+// getIPCollection returns the collection with the given name, or nil if it does not exist.
+func getIPCollection(ctx context.Context, config *Config, name string) (*IPCollection, error) {
+	url := fmt.Sprintf("%s/configs/network/v1/tenant/default/ipcollections/%s", config.Server, name)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.AddCookie(&http.Cookie{Name: "sid", Value: config.SID})
+
+	resp, err := config.Client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to read ip_collection %s: HTTP %d %s: %s", name, resp.StatusCode, resp.Status, bodyBytes)
+	}
+
+	collection := &IPCollection{}
+	if err := json.NewDecoder(resp.Body).Decode(collection); err != nil {
+		return nil, err
+	}
+	return collection, nil
+}
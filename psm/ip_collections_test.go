@@ -0,0 +1,237 @@
+package psm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		want    string
+		wantErr bool
+	}{
+		{address: "10.0.0.1", want: "10.0.0.1"},
+		{address: " 10.0.0.1 ", want: "10.0.0.1"},
+		{address: "10.0.0.5/24", want: "10.0.0.0/24"},
+		{address: "10.0.0.5/32", want: "10.0.0.5"},
+		{address: "2001:0db8:0000::0001", want: "2001:db8::1"},
+		{address: "2001:db8::1/128", want: "2001:db8::1"},
+		{address: "2001:db8::1/64", want: "2001:db8::/64"},
+		{address: "10.1.1.1 - 10.1.1.100", want: "10.1.1.1-10.1.1.100"},
+		{address: "10.1.1.100-10.1.1.1", wantErr: true},
+		{address: "10.1.1.1-2001:db8::1", wantErr: true},
+		{address: "10.0.0.0/33", wantErr: true},
+		{address: "10.0.0.256", wantErr: true},
+		{address: "any", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := normalizeAddress(tt.address)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("normalizeAddress(%q) error = %v, want error %v", tt.address, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("normalizeAddress(%q) = %q, want %q", tt.address, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeAddresses(t *testing.T) {
+	got := normalizeAddresses([]string{"10.0.0.5/24", "192.168.1.1/32", "10.0.0.0/24", "192.168.1.1", "not-an-address"})
+
+	want := []string{"10.0.0.0/24", "192.168.1.1", "not-an-address"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("normalizeAddresses() = %q, want %q", got, want)
+	}
+}
+
+func TestAggregateAddresses(t *testing.T) {
+	tests := []struct {
+		name      string
+		addresses []string
+		want      []string
+	}{
+		{
+			name:      "covered prefixes and addresses",
+			addresses: []string{"10.0.0.0/8", "10.1.0.0/16", "10.2.3.4"},
+			want:      []string{"10.0.0.0/8"},
+		},
+		{
+			name:      "adjacent prefixes",
+			addresses: []string{"198.51.100.0/25", "198.51.100.128/25"},
+			want:      []string{"198.51.100.0/24"},
+		},
+		{
+			name:      "merges cascade",
+			addresses: []string{"10.0.0.0", "10.0.0.1", "10.0.0.2/31", "10.0.0.4/30"},
+			want:      []string{"10.0.0.0/29"},
+		},
+		{
+			name:      "siblings of another parent are kept apart",
+			addresses: []string{"10.0.0.128/25", "10.0.1.0/25"},
+			want:      []string{"10.0.0.128/25", "10.0.1.0/25"},
+		},
+		{
+			name:      "ranges are kept",
+			addresses: []string{"10.1.1.1-10.1.1.100", "10.0.0.1"},
+			want:      []string{"10.0.0.1", "10.1.1.1-10.1.1.100"},
+		},
+		{
+			name:      "IPv6",
+			addresses: []string{"2001:db8::/33", "2001:db8:8000::/33", "2001:db8::1"},
+			want:      []string{"2001:db8::/32"},
+		},
+		{
+			name: "empty",
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := aggregateAddresses(tt.addresses); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("aggregateAddresses() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeIPCollectionAddresses(t *testing.T) {
+	addresses := []string{"10.0.0.1/32", "10.0.0.0/31", "10.0.0.1"}
+
+	if got, want := mergeIPCollectionAddresses(addresses, false), []string{"10.0.0.0/31", "10.0.0.1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("mergeIPCollectionAddresses(false) = %q, want %q", got, want)
+	}
+	if got, want := mergeIPCollectionAddresses(addresses, true), []string{"10.0.0.0/31"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("mergeIPCollectionAddresses(true) = %q, want %q", got, want)
+	}
+}
+
+func TestReadAddressesFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	path := write("feed.txt", "# threat feed\n\n203.0.113.0/24\n  198.51.100.7   # single host\n2001:db8::/32\n")
+	got, err := readAddressesFile(path)
+	if err != nil {
+		t.Fatalf("readAddressesFile() error = %v", err)
+	}
+	if want := []string{"203.0.113.0/24", "198.51.100.7", "2001:db8::/32"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("readAddressesFile() = %q, want %q", got, want)
+	}
+
+	path = write("invalid.txt", "203.0.113.0/24\n# comment\nexample.com\n")
+	if _, err := readAddressesFile(path); err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("readAddressesFile() error = %v, want an error for line 3", err)
+	}
+
+	if _, err := readAddressesFile(filepath.Join(dir, "missing.txt")); err == nil {
+		t.Errorf("readAddressesFile() of a missing file succeeded")
+	}
+}
+
+func TestHashAddresses(t *testing.T) {
+	if got := hashAddresses(nil); got != "" {
+		t.Errorf("hashAddresses(nil) = %q, want empty", got)
+	}
+
+	hash := hashAddresses([]string{"10.0.0.0/24", "10.1.0.0/24"})
+	if len(hash) != 64 {
+		t.Errorf("hashAddresses() = %q, want a SHA-256 hex digest", hash)
+	}
+	if got := hashAddresses([]string{"10.0.0.0/24", "10.1.0.0/24"}); got != hash {
+		t.Errorf("hashAddresses() is not stable: %q != %q", got, hash)
+	}
+	if got := hashAddresses([]string{"10.0.0.0/24"}); got == hash {
+		t.Errorf("hashAddresses() of a different collection = %q, want another hash", got)
+	}
+}
+
+func TestCheckIPCollectionNesting(t *testing.T) {
+	config := newTestServer(t, map[string]string{
+		"/configs/network/v1/tenant/default/ipcollections/ipc-b": `{"meta": {"name": "ipc-b"}, "spec": {"ipcollections": ["ipc-c"]}}`,
+		"/configs/network/v1/tenant/default/ipcollections/ipc-c": `{"meta": {"name": "ipc-c"}, "spec": {"ipcollections": ["ipc-a"]}}`,
+		"/configs/network/v1/tenant/default/ipcollections/ipc-d": `{"meta": {"name": "ipc-d"}, "spec": {"ipcollections": ["ipc-e"]}}`,
+		"/configs/network/v1/tenant/default/ipcollections/ipc-e": `{"meta": {"name": "ipc-e"}, "spec": {"ipcollections": ["ipc-d"]}}`,
+		"/configs/network/v1/tenant/default/ipcollections/ipc-f": `{"meta": {"name": "ipc-f"}, "spec": {}}`,
+	})
+	config.plannedIPCollections.Store("web", []string{"ipc-a"})
+	config.plannedIPCollections.Store("ipc-g", []string{"frontend"})
+
+	tests := []struct {
+		name     string
+		names    []string
+		children []string
+		wantErr  string
+	}{
+		{name: "no nesting", names: []string{"ipc-a"}},
+		{name: "nested collection without children", names: []string{"ipc-a"}, children: []string{"ipc-f"}},
+		{name: "unknown collection", names: []string{"ipc-a"}, children: []string{"missing"}},
+		{name: "self reference", names: []string{"ipc-a"}, children: []string{"ipc-a"}, wantErr: "ipc-a -> ipc-a"},
+		{name: "cycle through PSM", names: []string{"ipc-a"}, children: []string{"ipc-b"}, wantErr: "ipc-a -> ipc-b -> ipc-c -> ipc-a"},
+		{name: "cycle through a planned collection", names: []string{"ipc-a"}, children: []string{"web"}, wantErr: "ipc-a -> web -> ipc-a"},
+		{name: "cycle back to the display name", names: []string{"frontend", "ipc-h"}, children: []string{"ipc-g"}, wantErr: "ipc-h -> ipc-g -> frontend"},
+		{name: "new collection", children: []string{"ipc-b"}},
+		{name: "cycle not involving the collection", names: []string{"ipc-a"}, children: []string{"ipc-d"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkIPCollectionNesting(context.Background(), config, tt.names, tt.children)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("checkIPCollectionNesting() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("checkIPCollectionNesting() error = %v, want cycle %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestResourceIPCollectionCustomizeDiffPlannedCollections(t *testing.T) {
+	config := newTestServer(t, nil)
+
+	// A collection planned to nest ipc-b, which plans to nest it by its display name
+	state := map[string]string{"id": "ipc-a", "name": "ipc-a", "display_name": "web", "tenant": "default", "address_family": "IPv4"}
+	if _, err := testPlan(t, resourceIPCollection(), state, map[string]interface{}{"display_name": "web", "ip_collections": []interface{}{"ipc-b"}}, config); err != nil {
+		t.Fatalf("plan error = %v", err)
+	}
+	stateB := map[string]string{"id": "ipc-b", "name": "ipc-b", "display_name": "db", "tenant": "default", "address_family": "IPv4"}
+	if _, err := testPlan(t, resourceIPCollection(), stateB, map[string]interface{}{"display_name": "db", "ip_collections": []interface{}{"web"}}, config); err == nil {
+		t.Fatal("plan error = nil, want a cycle")
+	}
+
+	// Once renamed, the collection is no longer found by its earlier display name
+	if _, err := testPlan(t, resourceIPCollection(), state, map[string]interface{}{"display_name": "frontend", "ip_collections": []interface{}{"ipc-b"}}, config); err != nil {
+		t.Fatalf("plan error = %v", err)
+	}
+	if _, ok := config.plannedIPCollections.Load("web"); ok {
+		t.Errorf("planned collections still hold the earlier display name")
+	}
+	if _, err := testPlan(t, resourceIPCollection(), stateB, map[string]interface{}{"display_name": "db", "ip_collections": []interface{}{"web"}}, config); err != nil {
+		t.Errorf("plan error = %v, want no cycle through the earlier display name", err)
+	}
+
+	// Planned again without nested collections, it no longer forms a cycle either
+	if _, err := testPlan(t, resourceIPCollection(), state, map[string]interface{}{"display_name": "frontend"}, config); err != nil {
+		t.Fatalf("plan error = %v", err)
+	}
+	if _, err := testPlan(t, resourceIPCollection(), stateB, map[string]interface{}{"display_name": "db", "ip_collections": []interface{}{"frontend"}}, config); err != nil {
+		t.Errorf("plan error = %v, want no cycle once the other collection is planned without nesting", err)
+	}
+}
@@ -0,0 +1,292 @@
+package psm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func resourceIPAMPolicy() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceIPAMPolicyCreate,
+		ReadContext:   resourceIPAMPolicyRead,
+		UpdateContext: resourceIPAMPolicyUpdate,
+		DeleteContext: resourceIPAMPolicyDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceIPAMPolicyImport,
+		},
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Name of the IPAM policy, referenced by psm_network ipam_policy and psm_cluster bootstrap_ipam_policy",
+			},
+			"dhcp_server": {
+				Type:        schema.TypeList,
+				Required:    true,
+				MinItems:    1,
+				Description: "DHCP servers requests are relayed to",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"ip_address": {
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.IsIPAddress,
+						},
+						"virtual_router": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "default",
+							Description: "Virtual router (VRF) the DHCP server is reachable in",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+type IPAMPolicy struct {
+	Kind       interface{} `json:"kind"`
+	APIVersion interface{} `json:"api-version"`
+	Meta       struct {
+		Name            string      `json:"name"`
+		Tenant          string      `json:"tenant"`
+		Namespace       interface{} `json:"namespace"`
+		GenerationID    interface{} `json:"generation-id"`
+		ResourceVersion interface{} `json:"resource-version"`
+		UUID            string      `json:"uuid"`
+		Labels          interface{} `json:"labels"`
+		SelfLink        interface{} `json:"self-link"`
+	} `json:"meta"`
+	Spec struct {
+		Type      string `json:"type"`
+		DHCPRelay struct {
+			Servers []DHCPServer `json:"servers"`
+		} `json:"dhcp-relay"`
+	} `json:"spec"`
+}
+
+type DHCPServer struct {
+	IPAddress     string `json:"ip-address"`
+	VirtualRouter string `json:"virtual-router"`
+}
+
+func resourceIPAMPolicyCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	config := m.(*Config)
+	client := config.Client()
+
+	policy := expandIPAMPolicy(d)
+
+	jsonBytes, err := json.Marshal(policy)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	log.Printf("[DEBUG] Creating IPAM policy with name: %s", policy.Meta.Name)
+
+	req, err := http.NewRequestWithContext(ctx, "POST", config.Server+"/configs/network/v1/tenant/default/ipam-policies", bytes.NewBuffer(jsonBytes))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.AddCookie(&http.Cookie{Name: "sid", Value: config.SID})
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return diag.Errorf("failed to create IPAM policy: HTTP %d %s: %s", resp.StatusCode, resp.Status, bodyBytes)
+	}
+
+	responseBody := &IPAMPolicy{}
+	if err := json.NewDecoder(resp.Body).Decode(responseBody); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(responseBody.Meta.UUID)
+
+	return resourceIPAMPolicyRead(ctx, d, m)
+}
+
+func resourceIPAMPolicyRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	config := m.(*Config)
+	client := config.Client()
+
+	url := config.Server + "/configs/network/v1/tenant/default/ipam-policies/" + d.Get("name").(string)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	req.AddCookie(&http.Cookie{Name: "sid", Value: config.SID})
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		d.SetId("")
+		return nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return diag.Errorf("failed to read IPAM policy: HTTP %d %s: %s", resp.StatusCode, resp.Status, bodyBytes)
+	}
+
+	policy := &IPAMPolicy{}
+	if err := json.NewDecoder(resp.Body).Decode(policy); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.Set("name", policy.Meta.Name)
+
+	servers := make([]interface{}, 0, len(policy.Spec.DHCPRelay.Servers))
+	for _, server := range policy.Spec.DHCPRelay.Servers {
+		virtualRouter := server.VirtualRouter
+		if virtualRouter == "" {
+			virtualRouter = "default"
+		}
+		servers = append(servers, map[string]interface{}{
+			"ip_address":     server.IPAddress,
+			"virtual_router": virtualRouter,
+		})
+	}
+	if err := d.Set("dhcp_server", servers); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+func resourceIPAMPolicyUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	config := m.(*Config)
+	client := config.Client()
+
+	policy := expandIPAMPolicy(d)
+
+	jsonBytes, err := json.Marshal(policy)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	log.Printf("[DEBUG] Updating IPAM policy with name: %s", policy.Meta.Name)
+
+	req, err := http.NewRequestWithContext(ctx, "PUT", config.Server+"/configs/network/v1/tenant/default/ipam-policies/"+policy.Meta.Name, bytes.NewBuffer(jsonBytes))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.AddCookie(&http.Cookie{Name: "sid", Value: config.SID})
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return diag.Errorf("failed to update IPAM policy: HTTP %d %s: %s", resp.StatusCode, resp.Status, bodyBytes)
+	}
+
+	return resourceIPAMPolicyRead(ctx, d, m)
+}
+
+func resourceIPAMPolicyDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	config := m.(*Config)
+	client := config.Client()
+
+	url := config.Server + "/configs/network/v1/tenant/default/ipam-policies/" + d.Get("name").(string)
+
+	req, err := http.NewRequestWithContext(ctx, "DELETE", url, nil)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	req.AddCookie(&http.Cookie{Name: "sid", Value: config.SID})
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return diag.Errorf("failed to delete IPAM policy: HTTP %d %s: %s", resp.StatusCode, resp.Status, bodyBytes)
+	}
+
+	d.SetId("")
+
+	return nil
+}
+
+func resourceIPAMPolicyImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	config := m.(*Config)
+	client := config.Client()
+
+	// The import ID is the policy name, the resource ID is the UUID assigned by PSM
+	name := d.Id()
+	url := config.Server + "/configs/network/v1/tenant/default/ipam-policies/" + name
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.AddCookie(&http.Cookie{Name: "sid", Value: config.SID})
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to import IPAM policy %s: HTTP %d %s: %s", name, resp.StatusCode, resp.Status, bodyBytes)
+	}
+
+	policy := &IPAMPolicy{}
+	if err := json.NewDecoder(resp.Body).Decode(policy); err != nil {
+		return nil, err
+	}
+
+	d.SetId(policy.Meta.UUID)
+	d.Set("name", policy.Meta.Name)
+
+	return []*schema.ResourceData{d}, nil
+}
+
+func expandIPAMPolicy(d *schema.ResourceData) *IPAMPolicy {
+	policy := &IPAMPolicy{}
+	policy.Kind = "IPAMPolicy"
+	policy.Meta.Name = d.Get("name").(string)
+	policy.Meta.Tenant = "default"
+	policy.Spec.Type = "dhcp-relay"
+	policy.Spec.DHCPRelay.Servers = []DHCPServer{}
+
+	for _, v := range d.Get("dhcp_server").([]interface{}) {
+		server := v.(map[string]interface{})
+		policy.Spec.DHCPRelay.Servers = append(policy.Spec.DHCPRelay.Servers, DHCPServer{
+			IPAddress:     server["ip_address"].(string),
+			VirtualRouter: server["virtual_router"].(string),
+		})
+	}
+
+	return policy
+}
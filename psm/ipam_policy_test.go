@@ -0,0 +1,58 @@
+package psm
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestExpandIPAMPolicy(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceIPAMPolicy().Schema, map[string]interface{}{
+		"name": "relay",
+		"dhcp_server": []interface{}{
+			map[string]interface{}{"ip_address": "10.0.0.53"},
+			map[string]interface{}{"ip_address": "10.1.0.53", "virtual_router": "blue"},
+		},
+	})
+
+	policy := expandIPAMPolicy(d)
+
+	if policy.Meta.Name != "relay" || policy.Spec.Type != "dhcp-relay" {
+		t.Errorf("policy = %+v, want a dhcp-relay policy named relay", policy)
+	}
+	want := []DHCPServer{
+		{IPAddress: "10.0.0.53", VirtualRouter: "default"},
+		{IPAddress: "10.1.0.53", VirtualRouter: "blue"},
+	}
+	if !reflect.DeepEqual(policy.Spec.DHCPRelay.Servers, want) {
+		t.Errorf("servers = %+v, want %+v", policy.Spec.DHCPRelay.Servers, want)
+	}
+}
+
+func TestResourceIPAMPolicyRead(t *testing.T) {
+	config := newTestServer(t, map[string]string{
+		"/configs/network/v1/tenant/default/ipam-policies/relay": `{
+			"meta": {"name": "relay"},
+			"spec": {"type": "dhcp-relay", "dhcp-relay": {"servers": [
+				{"ip-address": "10.0.0.53"},
+				{"ip-address": "10.1.0.53", "virtual-router": "blue"}
+			]}}
+		}`,
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceIPAMPolicy().Schema, map[string]interface{}{"name": "relay"})
+	if diags := resourceIPAMPolicyRead(context.Background(), d, config); diags.HasError() {
+		t.Fatalf("read error = %v", diags)
+	}
+
+	// PSM leaves out the default virtual router, which must not show as a diff
+	want := []interface{}{
+		map[string]interface{}{"ip_address": "10.0.0.53", "virtual_router": "default"},
+		map[string]interface{}{"ip_address": "10.1.0.53", "virtual_router": "blue"},
+	}
+	if got := d.Get("dhcp_server"); !reflect.DeepEqual(got, want) {
+		t.Errorf("dhcp_server = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,126 @@
+package psm
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceIPCollectionExpanded() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceIPCollectionExpandedRead,
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Name of the IP collection to resolve",
+			},
+			"aggregate_addresses": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Merge overlapping and adjacent CIDR blocks in the result",
+			},
+			"display_name": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"address_family": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"addresses": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Sorted, de-duplicated addresses of the collection and every collection nested in it",
+			},
+			"ip_collections": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Names of every collection nested in the collection, directly or indirectly",
+			},
+		},
+	}
+}
+
+func dataSourceIPCollectionExpandedRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	config := m.(*Config)
+	name := d.Get("name").(string)
+
+	root, err := getIPCollection(ctx, config, name)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if root == nil {
+		return diag.Errorf("ip_collection %s not found", name)
+	}
+
+	addresses, nested, err := expandNestedIPCollection(ctx, config, root)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	addresses = normalizeAddresses(addresses)
+	if d.Get("aggregate_addresses").(bool) {
+		addresses = aggregateAddresses(addresses)
+	}
+
+	d.Set("display_name", root.Meta.DisplayName)
+	d.Set("address_family", root.Spec.AddressFamily)
+	if err := d.Set("addresses", addresses); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("ip_collections", nested); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(name)
+
+	return nil
+}
+
+// expandNestedIPCollection returns the addresses of a collection and of every collection nested in it, together
+// with the names of the nested collections. It fails on cycles and on nested collections that do not exist.
+func expandNestedIPCollection(ctx context.Context, config *Config, root *IPCollection) ([]string, []string, error) {
+	name := root.Meta.Name
+	var addresses, nested []string
+	visited := map[string]bool{name: true}
+
+	var expand func(path []string, collection *IPCollection) error
+	expand = func(path []string, collection *IPCollection) error {
+		addresses = append(addresses, collection.Spec.Addresses...)
+		for _, child := range collection.Spec.IPCollections {
+			if slices.Contains(path, child) {
+				return fmt.Errorf("ip_collections form a cycle: %s", strings.Join(append(slices.Clone(path), child), " -> "))
+			}
+			// Collections nested along several paths only contribute their addresses once
+			if visited[child] {
+				continue
+			}
+			visited[child] = true
+			nested = append(nested, child)
+
+			childCollection, err := getIPCollection(ctx, config, child)
+			if err != nil {
+				return err
+			}
+			if childCollection == nil {
+				return fmt.Errorf("ip_collection %s nested in %s not found", child, collection.Meta.Name)
+			}
+			if err := expand(append(slices.Clone(path), child), childCollection); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := expand([]string{name}, root); err != nil {
+		return nil, nil, err
+	}
+	return addresses, nested, nil
+}
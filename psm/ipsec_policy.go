@@ -34,6 +34,10 @@ func resourceIPSecPolicy() *schema.Resource {
 				Type:     schema.TypeString,
 				Required: true,
 			},
+			"name": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
 			"tunnel": {
 				Type:     schema.TypeList,
 				MaxItems: 1,
@@ -360,6 +364,9 @@ func resourceIPSecPolicyCreate(ctx context.Context, d *schema.ResourceData, m in
 	if err := d.Set("display_name", createdTunnel.Meta.DisplayName); err != nil {
 		return diag.FromErr(err)
 	}
+	if err := d.Set("name", createdTunnel.Meta.Name); err != nil {
+		return diag.FromErr(err)
+	}
 
 	// Use the same flatten function as in Read
 	flattenedSpec := flattenSpec(&createdTunnel.Spec, d)
@@ -410,6 +417,9 @@ func resourceIPSecPolicyRead(ctx context.Context, d *schema.ResourceData, m inte
 	if err := d.Set("display_name", tunnel.Meta.DisplayName); err != nil {
 		return diag.FromErr(fmt.Errorf("error setting display_name: %v", err))
 	}
+	if err := d.Set("name", tunnel.Meta.Name); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting name: %v", err))
+	}
 
 	if err := flattenSpec(&tunnel.Spec, d); err != nil {
 		return diag.FromErr(fmt.Errorf("error flattening IPSec Policy: %v", err))
@@ -475,6 +485,9 @@ func resourceIPSecPolicyUpdate(ctx context.Context, d *schema.ResourceData, m in
 	if err := d.Set("display_name", updatedTunnel.Meta.DisplayName); err != nil {
 		return diag.FromErr(err)
 	}
+	if err := d.Set("name", updatedTunnel.Meta.Name); err != nil {
+		return diag.FromErr(err)
+	}
 	if err := d.Set("tunnel", flattenSpec(&updatedTunnel.Spec, d)); err != nil {
 		return diag.FromErr(err)
 	}
@@ -561,6 +574,9 @@ func resourceIPSecPolicyImport(ctx context.Context, d *schema.ResourceData, m in
 	if err := d.Set("display_name", importedTunnel.Meta.DisplayName); err != nil {
 		return nil, err
 	}
+	if err := d.Set("name", importedTunnel.Meta.Name); err != nil {
+		return nil, err
+	}
 	if err := d.Set("tunnel", flattenSpec(&importedTunnel.Spec, d)); err != nil {
 		return nil, err
 	}
@@ -0,0 +1,232 @@
+package psm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func dataSourceLearnedEndpoints() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceLearnedEndpointsRead,
+		Schema: map[string]*schema.Schema{
+			"network": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return endpoints learned on this network",
+			},
+			"vlan": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntBetween(1, 4095),
+				Description:  "Only return endpoints learned on networks with this VLAN ID",
+			},
+			"host_name": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return endpoints learned on this host",
+			},
+			"unmanaged_only": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Only return endpoints that do not belong to a workload",
+			},
+			"endpoints": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"mac_address": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"ip_addresses": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"network": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"vlan": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"host_name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"workload": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"dsc": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+			"ip_addresses": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Sorted, de-duplicated addresses of the returned endpoints",
+			},
+			"mac_addresses": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Sorted, de-duplicated MAC addresses of the returned endpoints",
+			},
+		},
+	}
+}
+
+// LearnedEndpoint is an endpoint PSM knows of, either from a workload interface or learned by a DSC from traffic.
+type LearnedEndpoint struct {
+	Meta struct {
+		Name string `json:"name"`
+	} `json:"meta"`
+	Status struct {
+		WorkloadName   string   `json:"workload-name"`
+		Network        string   `json:"network"`
+		HomingHostName string   `json:"homing-host-name"`
+		MacAddress     string   `json:"mac-address"`
+		IPv4Addresses  []string `json:"ipv4-addresses"`
+		IPv6Addresses  []string `json:"ipv6-addresses"`
+		NodeUUID       string   `json:"node-uuid"`
+	} `json:"status"`
+}
+
+func dataSourceLearnedEndpointsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	config := m.(*Config)
+	network := d.Get("network").(string)
+	vlan := d.Get("vlan").(int)
+	hostName := d.Get("host_name").(string)
+	unmanagedOnly := d.Get("unmanaged_only").(bool)
+
+	endpoints, err := listLearnedEndpoints(ctx, config)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	networkVlans, err := listNetworkVlans(ctx, config)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	sort.Slice(endpoints, func(i, j int) bool { return endpoints[i].Meta.Name < endpoints[j].Meta.Name })
+
+	result := make([]interface{}, 0)
+	var addresses, macAddresses []string
+	for _, endpoint := range endpoints {
+		status := endpoint.Status
+		switch {
+		case network != "" && status.Network != network:
+			continue
+		case vlan != 0 && networkVlans[status.Network] != vlan:
+			continue
+		case hostName != "" && status.HomingHostName != hostName:
+			continue
+		case unmanagedOnly && status.WorkloadName != "":
+			continue
+		}
+
+		endpointAddresses := normalizeAddresses(append(slices.Clone(status.IPv4Addresses), status.IPv6Addresses...))
+		macAddress := normalizeMACAddress(status.MacAddress)
+		addresses = append(addresses, endpointAddresses...)
+		if macAddress != "" {
+			macAddresses = append(macAddresses, macAddress)
+		}
+
+		result = append(result, map[string]interface{}{
+			"name":         endpoint.Meta.Name,
+			"mac_address":  macAddress,
+			"ip_addresses": endpointAddresses,
+			"network":      status.Network,
+			"vlan":         networkVlans[status.Network],
+			"host_name":    status.HomingHostName,
+			"workload":     status.WorkloadName,
+			"dsc":          status.NodeUUID,
+		})
+	}
+
+	sort.Strings(macAddresses)
+	macAddresses = slices.Compact(macAddresses)
+
+	if err := d.Set("endpoints", result); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("ip_addresses", normalizeAddresses(addresses)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("mac_addresses", macAddresses); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(fmt.Sprintf("%s/%d/%s/%t", network, vlan, hostName, unmanagedOnly))
+
+	return nil
+}
+
+func listLearnedEndpoints(ctx context.Context, config *Config) ([]LearnedEndpoint, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", config.Server+"/configs/workload/v1/tenant/default/endpoints", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.AddCookie(&http.Cookie{Name: "sid", Value: config.SID})
+
+	resp, err := config.Client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to list endpoints: HTTP %d %s: %s", resp.StatusCode, resp.Status, bodyBytes)
+	}
+
+	var list struct {
+		Items []LearnedEndpoint `json:"items"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// listNetworkVlans returns the VLAN ID of every network by name, as endpoints only refer to their network.
+func listNetworkVlans(ctx context.Context, config *Config) (map[string]int, error) {
+	body, err := doNetworkRequest(ctx, config, "GET", config.Server+"/configs/network/v1/tenant/default/networks", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list networks: %w", err)
+	}
+
+	var list struct {
+		Items []Network `json:"items"`
+	}
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, err
+	}
+
+	vlans := make(map[string]int, len(list.Items))
+	for _, network := range list.Items {
+		vlans[network.Meta.Name] = network.Spec.VlanID
+	}
+	return vlans, nil
+}
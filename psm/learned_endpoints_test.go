@@ -0,0 +1,155 @@
+package psm
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func learnedEndpointsServer(t *testing.T) *Config {
+	return newTestServer(t, map[string]string{
+		"/configs/workload/v1/tenant/default/endpoints": `{"items": [
+			{"meta": {"name": "ep-3"}, "status": {"network": "db", "homing-host-name": "esx2", "mac-address": "00:50:56:00:00:03", "ipv4-addresses": ["10.2.0.3"], "node-uuid": "00ae.cd00.0002"}},
+			{"meta": {"name": "ep-1"}, "status": {"workload-name": "web01", "network": "web", "homing-host-name": "esx1", "mac-address": "0050.5600.0001", "ipv4-addresses": ["10.1.0.1/32", "10.1.0.1"], "ipv6-addresses": ["2001:db8::1"], "node-uuid": "00ae.cd00.0001"}},
+			{"meta": {"name": "ep-2"}, "status": {"network": "web", "homing-host-name": "esx2", "mac-address": "0050.5600.0002", "ipv4-addresses": ["10.1.0.2"], "node-uuid": "00ae.cd00.0002"}},
+			{"meta": {"name": "ep-4"}, "status": {"network": "web", "homing-host-name": "esx2", "mac-address": "0050.5600.0002", "ipv4-addresses": ["10.1.0.2"], "node-uuid": "00ae.cd00.0002"}}
+		]}`,
+		"/configs/network/v1/tenant/default/networks": `{"items": [
+			{"meta": {"name": "web"}, "spec": {"vlan-id": 100}},
+			{"meta": {"name": "db"}, "spec": {"vlan-id": 200}}
+		]}`,
+	})
+}
+
+func TestDataSourceLearnedEndpointsRead(t *testing.T) {
+	config := learnedEndpointsServer(t)
+
+	tests := []struct {
+		name          string
+		filters       map[string]interface{}
+		wantNames     []string
+		wantAddresses []string
+		wantMACs      []string
+		wantID        string
+	}{
+		{
+			name:          "all endpoints",
+			filters:       map[string]interface{}{},
+			wantNames:     []string{"ep-1", "ep-2", "ep-3", "ep-4"},
+			wantAddresses: []string{"10.1.0.1", "10.1.0.2", "10.2.0.3", "2001:db8::1"},
+			wantMACs:      []string{"0050.5600.0001", "0050.5600.0002", "0050.5600.0003"},
+			wantID:        "/0//false",
+		},
+		{
+			name:          "by network",
+			filters:       map[string]interface{}{"network": "db"},
+			wantNames:     []string{"ep-3"},
+			wantAddresses: []string{"10.2.0.3"},
+			wantMACs:      []string{"0050.5600.0003"},
+			wantID:        "db/0//false",
+		},
+		{
+			name:          "by VLAN",
+			filters:       map[string]interface{}{"vlan": 100},
+			wantNames:     []string{"ep-1", "ep-2", "ep-4"},
+			wantAddresses: []string{"10.1.0.1", "10.1.0.2", "2001:db8::1"},
+			wantMACs:      []string{"0050.5600.0001", "0050.5600.0002"},
+			wantID:        "/100//false",
+		},
+		{
+			name:          "by host",
+			filters:       map[string]interface{}{"host_name": "esx1"},
+			wantNames:     []string{"ep-1"},
+			wantAddresses: []string{"10.1.0.1", "2001:db8::1"},
+			wantMACs:      []string{"0050.5600.0001"},
+			wantID:        "/0/esx1/false",
+		},
+		{
+			name:          "unmanaged only",
+			filters:       map[string]interface{}{"vlan": 100, "unmanaged_only": true},
+			wantNames:     []string{"ep-2", "ep-4"},
+			wantAddresses: []string{"10.1.0.2"},
+			wantMACs:      []string{"0050.5600.0002"},
+			wantID:        "/100//true",
+		},
+		{
+			name:          "no match",
+			filters:       map[string]interface{}{"vlan": 300},
+			wantNames:     nil,
+			wantAddresses: []string{},
+			wantMACs:      []string{},
+			wantID:        "/300//false",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, dataSourceLearnedEndpoints().Schema, tt.filters)
+			if diags := dataSourceLearnedEndpointsRead(context.Background(), d, config); diags.HasError() {
+				t.Fatalf("read error = %v", diags)
+			}
+
+			var names []string
+			for _, v := range d.Get("endpoints").([]interface{}) {
+				names = append(names, v.(map[string]interface{})["name"].(string))
+			}
+			if !reflect.DeepEqual(names, tt.wantNames) {
+				t.Errorf("endpoints = %q, want %q", names, tt.wantNames)
+			}
+			if got := expandStringList(d.Get("ip_addresses").([]interface{})); !reflect.DeepEqual(got, tt.wantAddresses) {
+				t.Errorf("ip_addresses = %q, want %q", got, tt.wantAddresses)
+			}
+			if got := expandStringList(d.Get("mac_addresses").([]interface{})); !reflect.DeepEqual(got, tt.wantMACs) {
+				t.Errorf("mac_addresses = %q, want %q", got, tt.wantMACs)
+			}
+			if d.Id() != tt.wantID {
+				t.Errorf("id = %q, want %q", d.Id(), tt.wantID)
+			}
+		})
+	}
+}
+
+func TestDataSourceLearnedEndpointsReadEndpoint(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, dataSourceLearnedEndpoints().Schema, map[string]interface{}{"host_name": "esx1"})
+	if diags := dataSourceLearnedEndpointsRead(context.Background(), d, learnedEndpointsServer(t)); diags.HasError() {
+		t.Fatalf("read error = %v", diags)
+	}
+
+	want := map[string]interface{}{
+		"name":         "ep-1",
+		"mac_address":  "0050.5600.0001",
+		"ip_addresses": []interface{}{"10.1.0.1", "2001:db8::1"},
+		"network":      "web",
+		"vlan":         100,
+		"host_name":    "esx1",
+		"workload":     "web01",
+		"dsc":          "00ae.cd00.0001",
+	}
+	if got := d.Get("endpoints.0"); !reflect.DeepEqual(got, want) {
+		t.Errorf("endpoints.0 = %v, want %v", got, want)
+	}
+}
+
+func TestListNetworkVlans(t *testing.T) {
+	vlans, err := listNetworkVlans(context.Background(), learnedEndpointsServer(t))
+	if err != nil {
+		t.Fatalf("listNetworkVlans() error = %v", err)
+	}
+	if want := map[string]int{"web": 100, "db": 200}; !reflect.DeepEqual(vlans, want) {
+		t.Errorf("listNetworkVlans() = %v, want %v", vlans, want)
+	}
+
+	if _, err := listNetworkVlans(context.Background(), newTestServer(t, nil)); err == nil || !strings.Contains(err.Error(), "failed to list networks") {
+		t.Errorf("listNetworkVlans() error = %v, want failed to list networks", err)
+	}
+}
+
+func TestListLearnedEndpointsError(t *testing.T) {
+	_, err := listLearnedEndpoints(context.Background(), newTestServer(t, nil))
+	if err == nil || !strings.Contains(err.Error(), "failed to list endpoints: HTTP 404") {
+		t.Errorf("listLearnedEndpoints() error = %v, want HTTP 404", err)
+	}
+}
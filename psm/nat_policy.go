@@ -26,6 +26,10 @@ func resourceNATPolicy() *schema.Resource {
 				Type:     schema.TypeString,
 				Required: true,
 			},
+			"name": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
 			"rule": {
 				Type:     schema.TypeList,
 				Required: true,
@@ -158,11 +162,12 @@ type NATPolicy struct {
 	Kind       string `json:"kind"`
 	APIVersion string `json:"api-version"`
 	Meta       struct {
-		Name        string `json:"name"`
-		Tenant      string `json:"tenant"`
-		Namespace   string `json:"namespace"`
-		UUID        string `json:"uuid"`
-		DisplayName string `json:"display-name"`
+		Name            string `json:"name"`
+		Tenant          string `json:"tenant"`
+		Namespace       string `json:"namespace"`
+		UUID            string `json:"uuid"`
+		DisplayName     string `json:"display-name"`
+		ResourceVersion string `json:"resource-version,omitempty"`
 	} `json:"meta"`
 	Spec struct {
 		Rules                     []NatRule `json:"rules"`
@@ -290,6 +295,7 @@ func resourceNATPolicyRead(ctx context.Context, d *schema.ResourceData, m interf
 	}
 
 	d.Set("display_name", natPolicy.Meta.DisplayName)
+	d.Set("name", natPolicy.Meta.Name)
 
 	rules := make([]interface{}, len(natPolicy.Spec.Rules))
 	for i, rule := range natPolicy.Spec.Rules {
@@ -484,6 +490,7 @@ func resourceNATPolicyImport(ctx context.Context, d *schema.ResourceData, m inte
 	}
 
 	d.Set("display_name", natPolicy.Meta.DisplayName)
+	d.Set("name", natPolicy.Meta.Name)
 
 	rules := make([]interface{}, len(natPolicy.Spec.Rules))
 	for i, rule := range natPolicy.Spec.Rules {
@@ -7,11 +7,15 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"net"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 func resourceNetwork() *schema.Resource {
@@ -20,69 +24,275 @@ func resourceNetwork() *schema.Resource {
 		ReadContext:   resourceNetworkRead,
 		UpdateContext: resourceNetworkUpdate,
 		DeleteContext: resourceNetworkDelete,
-		Schema: map[string]*schema.Schema{
-			"name": {
-				Type:     schema.TypeString,
-				Required: true,
-				ForceNew: true,
-			},
-			"tenant": {
-				Type:     schema.TypeString,
-				Optional: true,
-				ForceNew: true,
-			},
-			"vlan_id": {
-				Type:     schema.TypeInt,
-				Required: true,
-				ForceNew: true,
-			},
-			"virtual_router": {
-				Type:     schema.TypeString,
-				Optional: true,
-				ForceNew: true,
-			},
-			"ingress_security_policy": {
-				Type:     schema.TypeString,
-				Optional: true,
-			},
-			"egress_security_policy": {
-				Type:     schema.TypeString,
-				Optional: true,
-			},
-			"connection_tracking_mode": {
-				Type:     schema.TypeString,
-				Optional: true,
-				ForceNew: false,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceNetworkImport,
+		},
+		CustomizeDiff: resourceNetworkCustomizeDiff,
+		SchemaVersion: 1,
+		StateUpgraders: []schema.StateUpgrader{
+			{
+				Version: 0,
+				Type:    resourceNetworkV0().CoreConfigSchema().ImpliedType(),
+				Upgrade: resourceNetworkStateUpgradeV0,
 			},
-			"allow_session_reuse": {
-				Type:     schema.TypeString,
-				Optional: true,
-				ForceNew: false,
+		},
+		Schema: resourceNetworkSchema(),
+	}
+}
+
+func resourceNetworkSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"name": {
+			Type:     schema.TypeString,
+			Required: true,
+			ForceNew: true,
+		},
+		"tenant": {
+			Type:     schema.TypeString,
+			Optional: true,
+			ForceNew: true,
+		},
+		"type": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      "bridged",
+			ForceNew:     true,
+			ValidateFunc: validation.StringInSlice([]string{"bridged", "routed"}, false),
+			Description:  "Network type, either bridged (VLAN only) or routed (subnets, gateways and VXLAN)",
+		},
+		"vlan_id": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			ValidateFunc: validation.IntBetween(0, 4095),
+			Description:  "VLAN ID of the network, required for bridged networks",
+		},
+		"ipv4_subnet": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			ForceNew:     true,
+			ValidateFunc: validation.IsCIDR,
+			Description:  "IPv4 subnet of a routed network in CIDR notation",
+		},
+		"ipv4_gateway": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			ValidateFunc: validation.IsIPv4Address,
+			Description:  "IPv4 gateway of a routed network, must be inside ipv4_subnet",
+		},
+		"ipv6_subnet": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			ForceNew:     true,
+			ValidateFunc: validation.IsCIDR,
+			Description:  "IPv6 subnet of a routed network in CIDR notation",
+		},
+		"ipv6_gateway": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			ValidateFunc: validation.IsIPv6Address,
+			Description:  "IPv6 gateway of a routed network, must be inside ipv6_subnet",
+		},
+		"vxlan_vni": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			ForceNew:     true,
+			ValidateFunc: validation.IntBetween(1, 16777215),
+			Description:  "VXLAN VNI of a routed network",
+		},
+		"ipam_policy": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "Name of the psm_ipam_policy used for DHCP relay on a routed network",
+		},
+		"route_import_export": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			MaxItems:    1,
+			Description: "EVPN route distinguisher and route targets of a routed network",
+			Elem:        routeImportExportResource(),
+		},
+		"virtual_router": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"recreate_on_move": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Replace the network instead of updating it in place when vlan_id or virtual_router change",
+		},
+		"ingress_security_policies": {
+			Type:             schema.TypeList,
+			Optional:         true,
+			Elem:             &schema.Schema{Type: schema.TypeString},
+			DiffSuppressFunc: suppressDeprecatedSecurityPolicies,
+			Description:      "Security policies applied to traffic entering the network, evaluated in order",
+		},
+		"egress_security_policies": {
+			Type:             schema.TypeList,
+			Optional:         true,
+			Elem:             &schema.Schema{Type: schema.TypeString},
+			DiffSuppressFunc: suppressDeprecatedSecurityPolicies,
+			Description:      "Security policies applied to traffic leaving the network, evaluated in order",
+		},
+		"ingress_security_policy": {
+			Type:          schema.TypeString,
+			Optional:      true,
+			Deprecated:    "Use ingress_security_policies instead",
+			ConflictsWith: []string{"ingress_security_policies"},
+			Description:   "Single security policy applied to traffic entering the network",
+		},
+		"egress_security_policy": {
+			Type:          schema.TypeString,
+			Optional:      true,
+			Deprecated:    "Use egress_security_policies instead",
+			ConflictsWith: []string{"egress_security_policies"},
+			Description:   "Single security policy applied to traffic leaving the network",
+		},
+		"connection_tracking_mode": {
+			Type:     schema.TypeString,
+			Optional: true,
+			ForceNew: false,
+		},
+		"allow_session_reuse": {
+			Type:     schema.TypeString,
+			Optional: true,
+			ForceNew: false,
+		},
+		"service_bypass": {
+			Type:     schema.TypeBool,
+			Optional: true,
+			ForceNew: false,
+		},
+		"ip_fragments_forwarding": {
+			Type:     schema.TypeString,
+			Optional: true,
+			ForceNew: false,
+		},
+		"orchestrators": {
+			Type:        schema.TypeSet,
+			Optional:    true,
+			Description: "Orchestrators the network is provisioned into, e.g. as a vCenter distributed port group",
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"name": {
+						Type:        schema.TypeString,
+						Required:    true,
+						Description: "Name of the psm_orchestrator",
+					},
+					"namespace": {
+						Type:        schema.TypeString,
+						Required:    true,
+						Description: "Orchestrator namespace, the datacenter for vCenter",
+					},
+				},
 			},
-			"service_bypass": {
-				Type:     schema.TypeBool,
+		},
+		"max_cps_per_dse": {
+			Type:             schema.TypeInt,
+			Optional:         true,
+			Default:          -1,
+			ValidateFunc:     validation.IntAtLeast(-1),
+			DiffSuppressFunc: suppressUnlimitedDSELimit,
+			Description:      "Maximum connections per second per DSE for this network, -1 or 0 for unlimited",
+		},
+		"max_sessions_per_dse": {
+			Type:             schema.TypeInt,
+			Optional:         true,
+			Default:          -1,
+			ValidateFunc:     validation.IntAtLeast(-1),
+			DiffSuppressFunc: suppressUnlimitedDSELimit,
+			Description:      "Maximum sessions per DSE for this network, -1 or 0 for unlimited",
+		},
+		"ingress_mirror_session": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "Name of mirror session to be applied on ingress traffic",
+		},
+		"egress_mirror_session": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "Name of mirror session to be applied on egress traffic",
+		},
+	}
+}
+
+// resourceNetworkV0 is the schema before ingress and egress security policies became lists. It is a frozen copy,
+// only used to decode state written by version 0, and must not follow later changes to the schema.
+func resourceNetworkV0() *schema.Resource {
+	routeDistinguisher := &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"type":           {Type: schema.TypeString, Optional: true},
+			"admin_value":    {Type: schema.TypeInt, Optional: true},
+			"assigned_value": {Type: schema.TypeInt, Optional: true},
+		},
+	}
+
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"name":         {Type: schema.TypeString, Required: true},
+			"tenant":       {Type: schema.TypeString, Optional: true},
+			"type":         {Type: schema.TypeString, Optional: true},
+			"vlan_id":      {Type: schema.TypeInt, Optional: true},
+			"ipv4_subnet":  {Type: schema.TypeString, Optional: true},
+			"ipv4_gateway": {Type: schema.TypeString, Optional: true},
+			"ipv6_subnet":  {Type: schema.TypeString, Optional: true},
+			"ipv6_gateway": {Type: schema.TypeString, Optional: true},
+			"vxlan_vni":    {Type: schema.TypeInt, Optional: true},
+			"ipam_policy":  {Type: schema.TypeString, Optional: true},
+			"route_import_export": {
+				Type:     schema.TypeList,
 				Optional: true,
-				ForceNew: false,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"address_family": {Type: schema.TypeString, Optional: true},
+						"rd_auto":        {Type: schema.TypeBool, Optional: true},
+						"rd":             {Type: schema.TypeList, Optional: true, Elem: routeDistinguisher},
+						"export_rts":     {Type: schema.TypeList, Optional: true, Elem: routeDistinguisher},
+						"import_rts":     {Type: schema.TypeList, Optional: true, Elem: routeDistinguisher},
+					},
+				},
 			},
-			"ip_fragments_forwarding": {
-				Type:     schema.TypeString,
+			"virtual_router":           {Type: schema.TypeString, Optional: true},
+			"ingress_security_policy":  {Type: schema.TypeString, Optional: true},
+			"egress_security_policy":   {Type: schema.TypeString, Optional: true},
+			"connection_tracking_mode": {Type: schema.TypeString, Optional: true},
+			"allow_session_reuse":      {Type: schema.TypeString, Optional: true},
+			"service_bypass":           {Type: schema.TypeBool, Optional: true},
+			"ip_fragments_forwarding":  {Type: schema.TypeString, Optional: true},
+			"orchestrators": {
+				Type:     schema.TypeSet,
 				Optional: true,
-				ForceNew: false,
-			},
-			"ingress_mirror_session": {
-				Type:        schema.TypeString,
-				Optional:    true,
-				Description: "Name of mirror session to be applied on ingress traffic",
-			},
-			"egress_mirror_session": {
-				Type:        schema.TypeString,
-				Optional:    true,
-				Description: "Name of mirror session to be applied on egress traffic",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name":      {Type: schema.TypeString, Required: true},
+						"namespace": {Type: schema.TypeString, Required: true},
+					},
+				},
 			},
+			"max_cps_per_dse":        {Type: schema.TypeInt, Optional: true},
+			"max_sessions_per_dse":   {Type: schema.TypeInt, Optional: true},
+			"ingress_mirror_session": {Type: schema.TypeString, Optional: true},
+			"egress_mirror_session":  {Type: schema.TypeString, Optional: true},
 		},
 	}
 }
 
+// resourceNetworkStateUpgradeV0 copies the single ingress_security_policy and egress_security_policy values into
+// the ingress_security_policies and egress_security_policies lists. The deprecated attributes keep their value, so
+// configurations still using them do not show a diff.
+func resourceNetworkStateUpgradeV0(ctx context.Context, rawState map[string]interface{}, meta interface{}) (map[string]interface{}, error) {
+	for from, to := range map[string]string{
+		"ingress_security_policy": "ingress_security_policies",
+		"egress_security_policy":  "egress_security_policies",
+	} {
+		if policy, ok := rawState[from].(string); ok && policy != "" {
+			rawState[to] = []interface{}{policy}
+		}
+	}
+	return rawState, nil
+}
+
 type Network struct {
 	Meta struct {
 		Kind            interface{} `json:"kind" default:"null"`
@@ -98,17 +308,17 @@ type Network struct {
 		DisplayName     interface{} `json:"display-name" default:"null"`
 	}
 	Spec struct {
-		Ipv4Subnet            interface{}   `json:"ipv4-subnet" default:"null"`
-		Ipv4Gateway           interface{}   `json:"ipv4-gateway" default:"null"`
-		Ipv6Subnet            interface{}   `json:"ipv6-subnet" default:"null"`
-		Ipv6Gateway           interface{}   `json:"ipv6-gateway" default:"null"`
-		VxlanVni              interface{}   `json:"vxlan-vni" default:"null"`
-		IpamPolicy            interface{}   `json:"ipam-policy" default:"null"`
-		Orchestrators         []interface{} `json:"orchestrators"`
-		IngressSecurityPolicy []interface{} `json:"ingress-security-policy" default:"null"`
-		EgressSecurityPolicy  []interface{} `json:"egress-security-policy" default:"null"`
-		IngressMirrorSession  []interface{} `json:"ingress-mirror-session,omitempty"`
-		EgressMirrorSession   []interface{} `json:"egress-mirror-session,omitempty"`
+		Ipv4Subnet            interface{}           `json:"ipv4-subnet" default:"null"`
+		Ipv4Gateway           interface{}           `json:"ipv4-gateway" default:"null"`
+		Ipv6Subnet            interface{}           `json:"ipv6-subnet" default:"null"`
+		Ipv6Gateway           interface{}           `json:"ipv6-gateway" default:"null"`
+		VxlanVni              interface{}           `json:"vxlan-vni" default:"null"`
+		IpamPolicy            interface{}           `json:"ipam-policy" default:"null"`
+		Orchestrators         []NetworkOrchestrator `json:"orchestrators"`
+		IngressSecurityPolicy []string              `json:"ingress-security-policy" default:"null"`
+		EgressSecurityPolicy  []string              `json:"egress-security-policy" default:"null"`
+		IngressMirrorSession  []interface{}         `json:"ingress-mirror-session,omitempty"`
+		EgressMirrorSession   []interface{}         `json:"egress-mirror-session,omitempty"`
 		FirewallProfile       struct {
 			MaximumCpsPerDistributedServicesEntity      int `json:"maximum-cps-per-distributed-services-entity" default:"-1"`
 			MaximumSessionsPerDistributedServicesEntity int `json:"maximum-sessions-per-distributed-services-entity" default:"-1"`
@@ -136,20 +346,24 @@ func resourceNetworkCreate(ctx context.Context, d *schema.ResourceData, m interf
 	network.Meta.Name = d.Get("name").(string)
 	network.Meta.Tenant = d.Get("tenant").(string)
 	network.Spec.VlanID = d.Get("vlan_id").(int)
-	network.Spec.Type = "bridged"
+	network.Spec.Type = d.Get("type").(string)
+	expandRoutedNetwork(d, network)
 	network.Meta.Namespace = "default"
 	network.Spec.VirtualRouter = d.Get("virtual_router").(string)
 	network.Spec.ConnectionTracking = d.Get("connection_tracking_mode").(string)
 	network.Spec.AllowSessionReuse = d.Get("allow_session_reuse").(string)
 	network.Spec.ServiceBypass = d.Get("service_bypass").(bool)
 	network.Spec.IpFragmentsForwarding = d.Get("ip_fragments_forwarding").(string)
+	network.Spec.Orchestrators = expandNetworkOrchestrators(d.Get("orchestrators").(*schema.Set))
+	network.Spec.FirewallProfile.MaximumCpsPerDistributedServicesEntity = d.Get("max_cps_per_dse").(int)
+	network.Spec.FirewallProfile.MaximumSessionsPerDistributedServicesEntity = d.Get("max_sessions_per_dse").(int)
 
-	// Check if the ingress_security_policy and egress_security_policy values are provided and set them
-	if v, ok := d.GetOk("ingress_security_policy"); ok {
-		network.Spec.IngressSecurityPolicy = []interface{}{v.(string)}
+	// Check if the ingress_security_policies and egress_security_policies values are provided and set them
+	if policies := networkSecurityPolicies(d, "ingress_security_policies"); len(policies) > 0 {
+		network.Spec.IngressSecurityPolicy = policies
 	}
-	if v, ok := d.GetOk("egress_security_policy"); ok {
-		network.Spec.EgressSecurityPolicy = []interface{}{v.(string)}
+	if policies := networkSecurityPolicies(d, "egress_security_policies"); len(policies) > 0 {
+		network.Spec.EgressSecurityPolicy = policies
 	}
 
 	// Check if mirror sessions are provided
@@ -242,6 +456,16 @@ func resourceNetworkRead(ctx context.Context, d *schema.ResourceData, m interfac
 
 	d.Set("name", network.Meta.Name)
 	d.Set("vlan_id", network.Spec.VlanID)
+	d.Set("ingress_security_policies", network.Spec.IngressSecurityPolicy)
+	d.Set("egress_security_policies", network.Spec.EgressSecurityPolicy)
+	if err := d.Set("orchestrators", flattenNetworkOrchestrators(network.Spec.Orchestrators)); err != nil {
+		return diag.FromErr(err)
+	}
+	d.Set("max_cps_per_dse", flattenDSELimit(network.Spec.FirewallProfile.MaximumCpsPerDistributedServicesEntity))
+	d.Set("max_sessions_per_dse", flattenDSELimit(network.Spec.FirewallProfile.MaximumSessionsPerDistributedServicesEntity))
+	if err := flattenRoutedNetwork(d, network); err != nil {
+		return diag.FromErr(err)
+	}
 
 	// Read mirror session configurations if present
 	if len(network.Spec.IngressMirrorSession) > 0 {
@@ -296,6 +520,10 @@ func resourceNetworkUpdate(ctx context.Context, d *schema.ResourceData, m interf
 		return diag.FromErr(err)
 	}
 
+	if d.HasChange("vlan_id") {
+		networkCurrent.Spec.VlanID = d.Get("vlan_id").(int)
+	}
+
 	if d.HasChange("virtual_router") {
 		if val, ok := d.GetOk("virtual_router"); ok {
 			newVirtualRouter := val.(string)
@@ -305,22 +533,16 @@ func resourceNetworkUpdate(ctx context.Context, d *schema.ResourceData, m interf
 		}
 	}
 
-	if d.HasChange("ingress_security_policy") {
-		if val, ok := d.GetOk("ingress_security_policy"); ok {
-			newIngressPolicy := val.(string)
-			networkCurrent.Spec.IngressSecurityPolicy = []interface{}{newIngressPolicy}
-		} else {
-			networkCurrent.Spec.IngressSecurityPolicy = nil
-		}
+	if d.HasChanges("ipv4_gateway", "ipv6_gateway", "ipam_policy", "route_import_export") {
+		expandRoutedNetwork(d, networkCurrent)
 	}
 
-	if d.HasChange("egress_security_policy") {
-		if val, ok := d.GetOk("egress_security_policy"); ok {
-			newEgressPolicy := val.(string)
-			networkCurrent.Spec.EgressSecurityPolicy = []interface{}{newEgressPolicy}
-		} else {
-			networkCurrent.Spec.EgressSecurityPolicy = nil
-		}
+	if d.HasChanges("ingress_security_policies", "ingress_security_policy") {
+		networkCurrent.Spec.IngressSecurityPolicy = networkSecurityPolicies(d, "ingress_security_policies")
+	}
+
+	if d.HasChanges("egress_security_policies", "egress_security_policy") {
+		networkCurrent.Spec.EgressSecurityPolicy = networkSecurityPolicies(d, "egress_security_policies")
 	}
 
 	if d.HasChange("connection_tracking_mode") {
@@ -354,6 +576,18 @@ func resourceNetworkUpdate(ctx context.Context, d *schema.ResourceData, m interf
 		networkCurrent.Spec.ServiceBypass = d.Get("service_bypass").(bool)
 	}
 
+	if d.HasChange("orchestrators") {
+		networkCurrent.Spec.Orchestrators = expandNetworkOrchestrators(d.Get("orchestrators").(*schema.Set))
+	}
+
+	if d.HasChange("max_cps_per_dse") {
+		networkCurrent.Spec.FirewallProfile.MaximumCpsPerDistributedServicesEntity = d.Get("max_cps_per_dse").(int)
+	}
+
+	if d.HasChange("max_sessions_per_dse") {
+		networkCurrent.Spec.FirewallProfile.MaximumSessionsPerDistributedServicesEntity = d.Get("max_sessions_per_dse").(int)
+	}
+
 	// Handle mirror session changes
 	if d.HasChange("ingress_mirror_session") {
 		if val, ok := d.GetOk("ingress_mirror_session"); ok {
@@ -397,6 +631,13 @@ func resourceNetworkUpdate(ctx context.Context, d *schema.ResourceData, m interf
 		if isDebugEnabled() {
 			log.Printf("[DEBUG] Network update failed with response: %s", bodyBytes)
 		}
+
+		// PSM validation errors come back as 400 or 412. For a move it does not allow, point at replacing the network
+		// instead, which is planned rather than attempted here after the update has failed.
+		if d.HasChanges("vlan_id", "virtual_router") && (respUpdate.StatusCode == http.StatusBadRequest || respUpdate.StatusCode == http.StatusPreconditionFailed) {
+			errMsg += "\n\nIf PSM does not allow vlan_id or virtual_router to be changed in place, set recreate_on_move = true " +
+				"to have Terraform replace the network instead."
+		}
 		return diag.Diagnostics{
 			{
 				Severity: diag.Error,
@@ -413,6 +654,40 @@ func resourceNetworkUpdate(ctx context.Context, d *schema.ResourceData, m interf
 	return resourceNetworkRead(ctx, d, m)
 }
 
+// doNetworkRequest sends payload, if any, as JSON and returns the response body of a successful request.
+func doNetworkRequest(ctx context.Context, config *Config, method, url string, payload interface{}) ([]byte, error) {
+	var body io.Reader
+	if payload != nil {
+		jsonBytes, err := json.Marshal(payload)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewBuffer(jsonBytes)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.AddCookie(&http.Cookie{Name: "sid", Value: config.SID})
+
+	resp, err := config.Client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d %s: %s", resp.StatusCode, resp.Status, bodyBytes)
+	}
+	return bodyBytes, nil
+}
+
 func resourceNetworkDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	config := m.(*Config)
 	client := config.Client()
@@ -444,3 +719,403 @@ func resourceNetworkDelete(ctx context.Context, d *schema.ResourceData, m interf
 
 	return nil
 }
+
+func resourceNetworkImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	config := m.(*Config)
+	client := config.Client()
+
+	// The import ID is the network name, the resource ID is the UUID assigned by PSM
+	name := d.Id()
+	url := config.Server + "/configs/network/v1/tenant/default/networks/" + name
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.AddCookie(&http.Cookie{Name: "sid", Value: config.SID})
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to import network %s: HTTP %d %s: %s", name, resp.StatusCode, resp.Status, bodyBytes)
+	}
+
+	network := &Network{}
+	if err := json.NewDecoder(resp.Body).Decode(network); err != nil {
+		return nil, err
+	}
+
+	d.SetId(network.Meta.UUID)
+	d.Set("name", network.Meta.Name)
+	d.Set("tenant", network.Meta.Tenant)
+	d.Set("virtual_router", network.Spec.VirtualRouter)
+	d.Set("connection_tracking_mode", network.Spec.ConnectionTracking)
+	d.Set("allow_session_reuse", network.Spec.AllowSessionReuse)
+	d.Set("service_bypass", network.Spec.ServiceBypass)
+	d.Set("ip_fragments_forwarding", network.Spec.IpFragmentsForwarding)
+
+	if diags := resourceNetworkRead(ctx, d, m); diags.HasError() {
+		return nil, fmt.Errorf("failed to read network %s: %s", name, diags[0].Summary)
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
+// NetworkOrchestrator attaches a network to a namespace of an orchestrator such as a vCenter datacenter.
+type NetworkOrchestrator struct {
+	Name      string `json:"orchestrator-name"`
+	Namespace string `json:"namespace"`
+}
+
+func expandNetworkOrchestrators(set *schema.Set) []NetworkOrchestrator {
+	var result []NetworkOrchestrator
+	for _, v := range set.List() {
+		orchestrator := v.(map[string]interface{})
+		result = append(result, NetworkOrchestrator{
+			Name:      orchestrator["name"].(string),
+			Namespace: orchestrator["namespace"].(string),
+		})
+	}
+	return result
+}
+
+func flattenNetworkOrchestrators(orchestrators []NetworkOrchestrator) []interface{} {
+	result := make([]interface{}, 0, len(orchestrators))
+	for _, orchestrator := range orchestrators {
+		result = append(result, map[string]interface{}{
+			"name":      orchestrator.Name,
+			"namespace": orchestrator.Namespace,
+		})
+	}
+	return result
+}
+
+// RouteImportExport is the EVPN route distinguisher and route target configuration of a routed network or VRF.
+type RouteImportExport struct {
+	AddressFamily string                `json:"address-family"`
+	RDAuto        bool                  `json:"rd-auto"`
+	RD            *RouteDistinguisher   `json:"rd,omitempty"`
+	ExportRTs     []*RouteDistinguisher `json:"export-rts"`
+	ImportRTs     []*RouteDistinguisher `json:"import-rts"`
+}
+
+type RouteDistinguisher struct {
+	Type          string `json:"type"`
+	AdminValue    int    `json:"admin-value"`
+	AssignedValue int    `json:"assigned-value"`
+}
+
+func routeDistinguisherResource() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"type": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringInSlice([]string{"type0", "type1", "type2"}, false),
+			},
+			"admin_value": {
+				Type:         schema.TypeInt,
+				Required:     true,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"assigned_value": {
+				Type:         schema.TypeInt,
+				Required:     true,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+		},
+	}
+}
+
+func routeImportExportResource() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"address_family": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "l2vpn-evpn",
+				ValidateFunc: validation.StringInSlice([]string{"l2vpn-evpn", "ipv4-unicast"}, false),
+			},
+			"rd_auto": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			"rd": {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem:     routeDistinguisherResource(),
+			},
+			"export_rts": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem:     routeDistinguisherResource(),
+			},
+			"import_rts": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem:     routeDistinguisherResource(),
+			},
+		},
+	}
+}
+
+func expandRouteDistinguishers(list []interface{}) []*RouteDistinguisher {
+	result := make([]*RouteDistinguisher, 0, len(list))
+	for _, v := range list {
+		rd := v.(map[string]interface{})
+		result = append(result, &RouteDistinguisher{
+			Type:          rd["type"].(string),
+			AdminValue:    rd["admin_value"].(int),
+			AssignedValue: rd["assigned_value"].(int),
+		})
+	}
+	return result
+}
+
+func flattenRouteDistinguishers(rds []*RouteDistinguisher) []interface{} {
+	result := make([]interface{}, 0, len(rds))
+	for _, rd := range rds {
+		if rd == nil {
+			continue
+		}
+		result = append(result, map[string]interface{}{
+			"type":           rd.Type,
+			"admin_value":    rd.AdminValue,
+			"assigned_value": rd.AssignedValue,
+		})
+	}
+	return result
+}
+
+func expandRouteImportExport(list []interface{}) *RouteImportExport {
+	if len(list) == 0 || list[0] == nil {
+		return nil
+	}
+	v := list[0].(map[string]interface{})
+	rie := &RouteImportExport{
+		AddressFamily: v["address_family"].(string),
+		RDAuto:        v["rd_auto"].(bool),
+		ExportRTs:     expandRouteDistinguishers(v["export_rts"].([]interface{})),
+		ImportRTs:     expandRouteDistinguishers(v["import_rts"].([]interface{})),
+	}
+	if rds := expandRouteDistinguishers(v["rd"].([]interface{})); len(rds) > 0 && !rie.RDAuto {
+		rie.RD = rds[0]
+	}
+	return rie
+}
+
+func flattenRouteImportExport(rie *RouteImportExport) []interface{} {
+	if rie == nil {
+		return nil
+	}
+	rd := []interface{}{}
+	if rie.RD != nil && !rie.RDAuto {
+		rd = flattenRouteDistinguishers([]*RouteDistinguisher{rie.RD})
+	}
+	return []interface{}{map[string]interface{}{
+		"address_family": rie.AddressFamily,
+		"rd_auto":        rie.RDAuto,
+		"rd":             rd,
+		"export_rts":     flattenRouteDistinguishers(rie.ExportRTs),
+		"import_rts":     flattenRouteDistinguishers(rie.ImportRTs),
+	}}
+}
+
+// expandRoutedNetwork copies the routed network attributes into the request. Unset attributes are sent as null so
+// that clearing a gateway or IPAM policy removes it from the network.
+func expandRoutedNetwork(d *schema.ResourceData, network *Network) {
+	optionalString := func(key string) interface{} {
+		if v, ok := d.GetOk(key); ok {
+			return v.(string)
+		}
+		return nil
+	}
+
+	network.Spec.Ipv4Subnet = optionalString("ipv4_subnet")
+	network.Spec.Ipv4Gateway = optionalString("ipv4_gateway")
+	network.Spec.Ipv6Subnet = optionalString("ipv6_subnet")
+	network.Spec.Ipv6Gateway = optionalString("ipv6_gateway")
+	network.Spec.IpamPolicy = optionalString("ipam_policy")
+	network.Spec.VxlanVni = nil
+	if v, ok := d.GetOk("vxlan_vni"); ok {
+		network.Spec.VxlanVni = v.(int)
+	}
+	network.Spec.RouteImportExport = nil
+	if rie := expandRouteImportExport(d.Get("route_import_export").([]interface{})); rie != nil {
+		network.Spec.RouteImportExport = rie
+	}
+}
+
+func flattenRoutedNetwork(d *schema.ResourceData, network *Network) error {
+	stringValue := func(v interface{}) string {
+		if s, ok := v.(string); ok {
+			return s
+		}
+		return ""
+	}
+
+	networkType := network.Spec.Type
+	if networkType == "" {
+		networkType = "bridged"
+	}
+	d.Set("type", networkType)
+	d.Set("ipv4_subnet", stringValue(network.Spec.Ipv4Subnet))
+	d.Set("ipv4_gateway", stringValue(network.Spec.Ipv4Gateway))
+	d.Set("ipv6_subnet", stringValue(network.Spec.Ipv6Subnet))
+	d.Set("ipv6_gateway", stringValue(network.Spec.Ipv6Gateway))
+	d.Set("ipam_policy", stringValue(network.Spec.IpamPolicy))
+
+	vni := 0
+	if v, ok := network.Spec.VxlanVni.(float64); ok {
+		vni = int(v)
+	}
+	d.Set("vxlan_vni", vni)
+
+	// RouteImportExport is decoded generically, round trip it through JSON to get the typed structure
+	var rie *RouteImportExport
+	if network.Spec.RouteImportExport != nil {
+		raw, err := json.Marshal(network.Spec.RouteImportExport)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(raw, &rie); err != nil {
+			return err
+		}
+	}
+	if _, configured := d.GetOk("route_import_export"); configured || (rie != nil && (len(rie.ExportRTs) > 0 || len(rie.ImportRTs) > 0 || !rie.RDAuto)) {
+		return d.Set("route_import_export", flattenRouteImportExport(rie))
+	}
+	return d.Set("route_import_export", nil)
+}
+
+// resourceNetworkCustomizeDiff checks at plan time that the attributes set match the network type, so that a
+// bridged network with subnets, or a routed network without any, is rejected before anything is sent to PSM. With
+// recreate_on_move set, a change of vlan_id or virtual_router is planned as a replacement rather than an update.
+func resourceNetworkCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if d.Id() != "" && d.Get("recreate_on_move").(bool) {
+		for _, key := range []string{"vlan_id", "virtual_router"} {
+			if d.HasChange(key) {
+				if err := d.ForceNew(key); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if !d.NewValueKnown("type") {
+		return nil
+	}
+
+	routedOnly := []string{"ipv4_subnet", "ipv4_gateway", "ipv6_subnet", "ipv6_gateway", "vxlan_vni", "ipam_policy", "route_import_export"}
+	switch d.Get("type").(string) {
+	case "bridged":
+		if d.NewValueKnown("vlan_id") && d.Get("vlan_id").(int) == 0 {
+			return fmt.Errorf("vlan_id is required for bridged networks")
+		}
+		for _, key := range routedOnly {
+			if _, ok := d.GetOk(key); ok {
+				return fmt.Errorf("%s can only be set on routed networks, set type = \"routed\"", key)
+			}
+		}
+	case "routed":
+		ipv4Subnet, ipv6Subnet := d.Get("ipv4_subnet").(string), d.Get("ipv6_subnet").(string)
+		if d.NewValueKnown("ipv4_subnet") && d.NewValueKnown("ipv6_subnet") && ipv4Subnet == "" && ipv6Subnet == "" {
+			return fmt.Errorf("routed networks require ipv4_subnet and/or ipv6_subnet")
+		}
+		if err := validateNetworkGateway(d, "ipv4_subnet", "ipv4_gateway", false); err != nil {
+			return err
+		}
+		if err := validateNetworkGateway(d, "ipv6_subnet", "ipv6_gateway", true); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateNetworkGateway(d *schema.ResourceDiff, subnetKey, gatewayKey string, ipv6 bool) error {
+	if !d.NewValueKnown(subnetKey) || !d.NewValueKnown(gatewayKey) {
+		return nil
+	}
+	subnet, gateway := d.Get(subnetKey).(string), d.Get(gatewayKey).(string)
+	if subnet == "" {
+		if gateway != "" {
+			return fmt.Errorf("%s requires %s to be set", gatewayKey, subnetKey)
+		}
+		return nil
+	}
+
+	ip, ipNet, err := net.ParseCIDR(subnet)
+	if err != nil {
+		return fmt.Errorf("%s %q is not a valid CIDR: %v", subnetKey, subnet, err)
+	}
+	if (ip.To4() == nil) != ipv6 {
+		family := "IPv4"
+		if ipv6 {
+			family = "IPv6"
+		}
+		return fmt.Errorf("%s %q must be an %s subnet", subnetKey, subnet, family)
+	}
+	if gateway != "" && !ipNet.Contains(net.ParseIP(gateway)) {
+		return fmt.Errorf("%s %s is not inside %s %s", gatewayKey, gateway, subnetKey, subnet)
+	}
+	return nil
+}
+
+// PSM returns 0 for a per-DSE limit that was never set, which like -1 means unlimited.
+func isUnlimitedDSELimit(v int) bool {
+	return v <= 0
+}
+
+// flattenDSELimit reads a per-DSE limit from PSM, reporting an unset limit as -1.
+func flattenDSELimit(v int) int {
+	if isUnlimitedDSELimit(v) {
+		return -1
+	}
+	return v
+}
+
+// suppressUnlimitedDSELimit treats 0, -1 and an empty value of a per-DSE limit as the same unlimited setting, so that
+// networks created before these attributes existed, and limits PSM reports as 0, do not show a diff.
+func suppressUnlimitedDSELimit(k, old, new string, d *schema.ResourceData) bool {
+	unlimited := func(s string) bool {
+		v, err := strconv.Atoi(s)
+		return s == "" || (err == nil && isUnlimitedDSELimit(v))
+	}
+	return unlimited(old) && unlimited(new)
+}
+
+// networkSecurityPolicies returns the ingress or egress security policies to send to PSM, taken from the deprecated
+// single attribute when the configuration still uses it.
+func networkSecurityPolicies(d *schema.ResourceData, list string) []string {
+	if policy := d.Get(deprecatedSecurityPolicyAttributes[list]).(string); policy != "" {
+		return []string{policy}
+	}
+	return expandStringList(d.Get(list).([]interface{}))
+}
+
+// deprecatedSecurityPolicyAttributes maps the security policy lists to the single attributes they replace.
+var deprecatedSecurityPolicyAttributes = map[string]string{
+	"ingress_security_policies": "ingress_security_policy",
+	"egress_security_policies":  "egress_security_policy",
+}
+
+// suppressDeprecatedSecurityPolicies hides the diff of a security policy list while the configuration sets the
+// deprecated single attribute instead, which then determines the policy sent to PSM.
+func suppressDeprecatedSecurityPolicies(k, old, new string, d *schema.ResourceData) bool {
+	single := deprecatedSecurityPolicyAttributes[strings.SplitN(k, ".", 2)[0]]
+	// While planning, Get falls back to the state for attributes absent from the configuration
+	if config := d.GetRawConfig(); !config.IsNull() {
+		policy := config.GetAttr(single)
+		return !policy.IsNull() && (!policy.IsKnown() || policy.AsString() != "")
+	}
+	return d.Get(single).(string) != ""
+}
@@ -0,0 +1,382 @@
+package psm
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestRouteImportExportRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []interface{}
+		want *RouteImportExport
+	}{
+		{
+			name: "not configured",
+			in:   nil,
+			want: nil,
+		},
+		{
+			name: "automatic route distinguisher",
+			in: []interface{}{map[string]interface{}{
+				"address_family": "l2vpn-evpn",
+				"rd_auto":        true,
+				"rd":             []interface{}{},
+				"export_rts":     []interface{}{map[string]interface{}{"type": "type2", "admin_value": 65000, "assigned_value": 10010}},
+				"import_rts":     []interface{}{map[string]interface{}{"type": "type2", "admin_value": 65000, "assigned_value": 10010}},
+			}},
+			want: &RouteImportExport{
+				AddressFamily: "l2vpn-evpn",
+				RDAuto:        true,
+				ExportRTs:     []*RouteDistinguisher{{Type: "type2", AdminValue: 65000, AssignedValue: 10010}},
+				ImportRTs:     []*RouteDistinguisher{{Type: "type2", AdminValue: 65000, AssignedValue: 10010}},
+			},
+		},
+		{
+			name: "explicit route distinguisher",
+			in: []interface{}{map[string]interface{}{
+				"address_family": "ipv4-unicast",
+				"rd_auto":        false,
+				"rd":             []interface{}{map[string]interface{}{"type": "type0", "admin_value": 100, "assigned_value": 1}},
+				"export_rts":     []interface{}{},
+				"import_rts":     []interface{}{},
+			}},
+			want: &RouteImportExport{
+				AddressFamily: "ipv4-unicast",
+				RD:            &RouteDistinguisher{Type: "type0", AdminValue: 100, AssignedValue: 1},
+				ExportRTs:     []*RouteDistinguisher{},
+				ImportRTs:     []*RouteDistinguisher{},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := expandRouteImportExport(tt.in)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("expandRouteImportExport() = %+v, want %+v", got, tt.want)
+			}
+			if flattened := flattenRouteImportExport(got); !reflect.DeepEqual(flattened, tt.in) && !(flattened == nil && tt.in == nil) {
+				t.Errorf("flattenRouteImportExport() = %v, want %v", flattened, tt.in)
+			}
+		})
+	}
+}
+
+func TestResourceNetworkCustomizeDiff(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  map[string]interface{}
+		wantErr string
+	}{
+		{
+			name:   "bridged",
+			config: map[string]interface{}{"name": "web", "vlan_id": 100},
+		},
+		{
+			name:    "bridged without vlan",
+			config:  map[string]interface{}{"name": "web"},
+			wantErr: "vlan_id is required for bridged networks",
+		},
+		{
+			name:    "bridged with subnet",
+			config:  map[string]interface{}{"name": "web", "vlan_id": 100, "ipv4_subnet": "10.0.0.0/24"},
+			wantErr: "ipv4_subnet can only be set on routed networks",
+		},
+		{
+			name:   "routed",
+			config: map[string]interface{}{"name": "web", "type": "routed", "ipv4_subnet": "10.0.0.0/24", "ipv4_gateway": "10.0.0.1", "ipv6_subnet": "2001:db8::/64", "ipv6_gateway": "2001:db8::1"},
+		},
+		{
+			name:    "routed without subnet",
+			config:  map[string]interface{}{"name": "web", "type": "routed"},
+			wantErr: "routed networks require ipv4_subnet and/or ipv6_subnet",
+		},
+		{
+			name:    "gateway outside subnet",
+			config:  map[string]interface{}{"name": "web", "type": "routed", "ipv4_subnet": "10.0.0.0/24", "ipv4_gateway": "10.0.1.1"},
+			wantErr: "ipv4_gateway 10.0.1.1 is not inside ipv4_subnet 10.0.0.0/24",
+		},
+		{
+			name:    "gateway without subnet",
+			config:  map[string]interface{}{"name": "web", "type": "routed", "ipv4_subnet": "10.0.0.0/24", "ipv6_gateway": "2001:db8::1"},
+			wantErr: "ipv6_gateway requires ipv6_subnet to be set",
+		},
+		{
+			name:    "IPv6 subnet in ipv4_subnet",
+			config:  map[string]interface{}{"name": "web", "type": "routed", "ipv4_subnet": "2001:db8::/64"},
+			wantErr: "must be an IPv4 subnet",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := testPlan(t, resourceNetwork(), nil, tt.config, nil)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("plan error = %v, want none", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("plan error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFlattenDSELimit(t *testing.T) {
+	tests := []struct {
+		in, want int
+	}{
+		{-1, -1},
+		{0, -1},
+		{1, 1},
+		{50000, 50000},
+	}
+
+	for _, tt := range tests {
+		if got := flattenDSELimit(tt.in); got != tt.want {
+			t.Errorf("flattenDSELimit(%d) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSuppressUnlimitedDSELimit(t *testing.T) {
+	tests := []struct {
+		old, new string
+		want     bool
+	}{
+		{"-1", "-1", true},
+		{"0", "-1", true},
+		{"-1", "0", true},
+		{"", "-1", true},
+		{"", "0", true},
+		{"-1", "1000", false},
+		{"0", "1000", false},
+		{"1000", "-1", false},
+		{"1000", "2000", false},
+	}
+
+	for _, tt := range tests {
+		if got := suppressUnlimitedDSELimit("max_cps_per_dse", tt.old, tt.new, nil); got != tt.want {
+			t.Errorf("suppressUnlimitedDSELimit(%q, %q) = %v, want %v", tt.old, tt.new, got, tt.want)
+		}
+	}
+}
+
+func TestNetworkOrchestratorsRoundTrip(t *testing.T) {
+	orchestratorsSchema := resourceNetworkSchema()["orchestrators"]
+
+	tests := []struct {
+		name string
+		in   []NetworkOrchestrator
+	}{
+		{"none", nil},
+		{"one", []NetworkOrchestrator{{Name: "vcenter01", Namespace: "Datacenter1"}}},
+		{"several", []NetworkOrchestrator{
+			{Name: "vcenter01", Namespace: "Datacenter1"},
+			{Name: "vcenter01", Namespace: "Datacenter2"},
+			{Name: "vcenter02", Namespace: "Datacenter1"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set := schema.NewSet(schema.HashResource(orchestratorsSchema.Elem.(*schema.Resource)), flattenNetworkOrchestrators(tt.in))
+			if set.Len() != len(tt.in) {
+				t.Fatalf("flattenNetworkOrchestrators() has %d distinct elements, want %d", set.Len(), len(tt.in))
+			}
+
+			got := expandNetworkOrchestrators(set)
+			sort.Slice(got, func(i, j int) bool {
+				return got[i].Name+"/"+got[i].Namespace < got[j].Name+"/"+got[j].Namespace
+			})
+			if len(got) != len(tt.in) || (len(got) > 0 && !reflect.DeepEqual(got, tt.in)) {
+				t.Errorf("expandNetworkOrchestrators() = %v, want %v", got, tt.in)
+			}
+		})
+	}
+}
+
+func TestResourceNetworkStateUpgradeV0(t *testing.T) {
+	tests := []struct {
+		name  string
+		state map[string]interface{}
+		want  map[string]interface{}
+	}{
+		{
+			name:  "no policies",
+			state: map[string]interface{}{"name": "web", "ingress_security_policy": ""},
+			want:  map[string]interface{}{"name": "web", "ingress_security_policy": ""},
+		},
+		{
+			name:  "both policies",
+			state: map[string]interface{}{"name": "web", "ingress_security_policy": "in", "egress_security_policy": "out"},
+			want: map[string]interface{}{
+				"name":                      "web",
+				"ingress_security_policy":   "in",
+				"egress_security_policy":    "out",
+				"ingress_security_policies": []interface{}{"in"},
+				"egress_security_policies":  []interface{}{"out"},
+			},
+		},
+		{
+			name:  "ingress only",
+			state: map[string]interface{}{"name": "web", "ingress_security_policy": "in"},
+			want: map[string]interface{}{
+				"name":                      "web",
+				"ingress_security_policy":   "in",
+				"ingress_security_policies": []interface{}{"in"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resourceNetworkStateUpgradeV0(context.Background(), tt.state, nil)
+			if err != nil {
+				t.Fatalf("resourceNetworkStateUpgradeV0() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("resourceNetworkStateUpgradeV0() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResourceNetworkSecurityPoliciesPlan(t *testing.T) {
+	// State of a network upgraded from schema version 0, which still has the deprecated attribute set
+	state := map[string]string{
+		"id":                          "web",
+		"name":                        "web",
+		"type":                        "bridged",
+		"vlan_id":                     "100",
+		"max_cps_per_dse":             "-1",
+		"max_sessions_per_dse":        "-1",
+		"ingress_security_policy":     "in",
+		"ingress_security_policies.#": "1",
+		"ingress_security_policies.0": "in",
+	}
+
+	tests := []struct {
+		name        string
+		config      map[string]interface{}
+		wantChanged []string
+		wantPolicy  []string
+	}{
+		{
+			name:       "deprecated attribute unchanged",
+			config:     map[string]interface{}{"name": "web", "vlan_id": 100, "ingress_security_policy": "in"},
+			wantPolicy: []string{"in"},
+		},
+		{
+			name:        "deprecated attribute changed",
+			config:      map[string]interface{}{"name": "web", "vlan_id": 100, "ingress_security_policy": "in2"},
+			wantChanged: []string{"ingress_security_policy"},
+			wantPolicy:  []string{"in2"},
+		},
+		{
+			name:        "replaced by the same list",
+			config:      map[string]interface{}{"name": "web", "vlan_id": 100, "ingress_security_policies": []interface{}{"in"}},
+			wantChanged: []string{"ingress_security_policy"},
+			wantPolicy:  []string{"in"},
+		},
+		{
+			name:        "replaced by another list",
+			config:      map[string]interface{}{"name": "web", "vlan_id": 100, "ingress_security_policies": []interface{}{"in", "baseline"}},
+			wantChanged: []string{"ingress_security_policies.#", "ingress_security_policies.1", "ingress_security_policy"},
+			wantPolicy:  []string{"in", "baseline"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff, err := testPlan(t, resourceNetwork(), state, tt.config, nil)
+			if err != nil {
+				t.Fatalf("plan error = %v", err)
+			}
+
+			var changed []string
+			if diff != nil {
+				for key, attr := range diff.Attributes {
+					if strings.Contains(key, "security_polic") && attr.Old != attr.New {
+						changed = append(changed, key)
+					}
+				}
+			}
+			sort.Strings(changed)
+			if !reflect.DeepEqual(changed, tt.wantChanged) {
+				t.Errorf("changed attributes = %q, want %q", changed, tt.wantChanged)
+			}
+
+			d := plannedData(t, resourceNetwork(), state, diff)
+			if got := networkSecurityPolicies(d, "ingress_security_policies"); !reflect.DeepEqual(got, tt.wantPolicy) {
+				t.Errorf("networkSecurityPolicies() = %q, want %q", got, tt.wantPolicy)
+			}
+		})
+	}
+}
+
+func TestResourceNetworkRecreateOnMovePlan(t *testing.T) {
+	state := map[string]string{
+		"id":                   "web",
+		"name":                 "web",
+		"type":                 "bridged",
+		"vlan_id":              "100",
+		"virtual_router":       "default",
+		"max_cps_per_dse":      "-1",
+		"max_sessions_per_dse": "-1",
+	}
+
+	tests := []struct {
+		name        string
+		config      map[string]interface{}
+		wantReplace bool
+	}{
+		{
+			name:   "vlan_id changed in place",
+			config: map[string]interface{}{"name": "web", "vlan_id": 200, "virtual_router": "default"},
+		},
+		{
+			name:        "vlan_id changed with recreate_on_move",
+			config:      map[string]interface{}{"name": "web", "vlan_id": 200, "virtual_router": "default", "recreate_on_move": true},
+			wantReplace: true,
+		},
+		{
+			name:        "virtual_router changed with recreate_on_move",
+			config:      map[string]interface{}{"name": "web", "vlan_id": 100, "virtual_router": "blue", "recreate_on_move": true},
+			wantReplace: true,
+		},
+		{
+			name:   "other change with recreate_on_move",
+			config: map[string]interface{}{"name": "web", "vlan_id": 100, "virtual_router": "default", "recreate_on_move": true, "service_bypass": true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff, err := testPlan(t, resourceNetwork(), state, tt.config, nil)
+			if err != nil {
+				t.Fatalf("plan error = %v", err)
+			}
+			if diff == nil {
+				t.Fatal("plan has no changes")
+			}
+			if got := diff.RequiresNew(); got != tt.wantReplace {
+				t.Errorf("RequiresNew() = %v, want %v", got, tt.wantReplace)
+			}
+		})
+	}
+
+	// Creating a network with recreate_on_move set has nothing to replace
+	diff, err := testPlan(t, resourceNetwork(), nil, map[string]interface{}{"name": "web", "vlan_id": 100, "recreate_on_move": true}, nil)
+	if err != nil {
+		t.Fatalf("plan error = %v", err)
+	}
+	if attr := diff.Attributes["vlan_id"]; attr == nil || attr.RequiresNew {
+		t.Errorf("vlan_id diff = %+v, want a plain create", attr)
+	}
+}
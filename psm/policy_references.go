@@ -0,0 +1,338 @@
+package psm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// Kinds of objects that security and NAT policy rules reference by name.
+const (
+	referenceApp           = "app"
+	referenceIPCollection  = "ip collection"
+	referenceRuleProfile   = "rule profile"
+	referenceWorkloadGroup = "workload group"
+)
+
+// maxPolicyUpdateAttempts bounds how often a policy is read and written again when a concurrent edit makes PSM
+// reject the update with a conflict.
+const maxPolicyUpdateAttempts = 5
+
+// forceDetachSchema is the force_detach argument of resources that policy rules can reference.
+func forceDetachSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+		Description: "On delete, remove references from security and NAT policy rules instead of failing",
+	}
+}
+
+// policyReference is a policy rule that references an object.
+type policyReference struct {
+	PolicyType string
+	Policy     string
+	Rule       string
+}
+
+func (r policyReference) String() string {
+	return fmt.Sprintf("%s %s, rule %s", r.PolicyType, r.Policy, r.Rule)
+}
+
+// ruleLabel names a rule in diagnostics, falling back to its position as rules need not have a name.
+func ruleLabel(name string, i int) string {
+	if name != "" {
+		return name
+	}
+	return fmt.Sprintf("#%d", i+1)
+}
+
+// checkPolicyReferences is called before deleting an object that policy rules can reference. PSM refuses to delete
+// such an object with an error that does not say where it is used, so this lists the referencing rules instead, or
+// removes the references when force_detach is set. References are only removed if no rule is left matching any
+// source, destination or app as a result; otherwise nothing is changed and the rules are listed.
+func checkPolicyReferences(ctx context.Context, d *schema.ResourceData, config *Config, kind, name string) diag.Diagnostics {
+	policies, err := listSecurityPolicies(ctx, config)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	var natPolicies []NATPolicy
+	if kind == referenceIPCollection {
+		if natPolicies, err = listNATPolicies(ctx, config); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	// Detaching is first tried on the listed policies, so that nothing is changed if any rule cannot be detached
+	var refs, blocked, securityPolicyNames, natPolicyNames []string
+	for _, policy := range policies {
+		for i := range policy.Spec.Rules {
+			rule := &policy.Spec.Rules[i]
+			if !rule.references(kind, name) {
+				continue
+			}
+			ref := policyReference{"security policy", policy.Meta.Name, ruleLabel(rule.Name, i)}
+			refs = append(refs, ref.String())
+			if !containsString(securityPolicyNames, policy.Meta.Name) {
+				securityPolicyNames = append(securityPolicyNames, policy.Meta.Name)
+			}
+			if err := rule.detach(kind, name); err != nil {
+				blocked = append(blocked, fmt.Sprintf("%s: %v", ref, err))
+			}
+		}
+	}
+	for _, policy := range natPolicies {
+		for i := range policy.Spec.Rules {
+			rule := &policy.Spec.Rules[i]
+			if !rule.references(name) {
+				continue
+			}
+			ref := policyReference{"NAT policy", policy.Meta.Name, ruleLabel(rule.Name, i)}
+			refs = append(refs, ref.String())
+			if !containsString(natPolicyNames, policy.Meta.Name) {
+				natPolicyNames = append(natPolicyNames, policy.Meta.Name)
+			}
+			if err := rule.detach(name); err != nil {
+				blocked = append(blocked, fmt.Sprintf("%s: %v", ref, err))
+			}
+		}
+	}
+	if len(refs) == 0 {
+		return nil
+	}
+
+	if !d.Get("force_detach").(bool) {
+		return diag.Diagnostics{{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("%s %s is still referenced by %d policy rule(s)", kind, name, len(refs)),
+			Detail: fmt.Sprintf("It is referenced by:\n%s\n\nRemove these references first, or set force_detach = true "+
+				"to remove them when deleting the %s.", bulletList(refs), kind),
+		}}
+	}
+	if len(blocked) > 0 {
+		return diag.Diagnostics{{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("%s %s cannot be detached from %d policy rule(s)", kind, name, len(blocked)),
+			Detail: fmt.Sprintf("Removing the reference would leave these rules matching any traffic:\n%s\n\nChange or "+
+				"remove the rules first. No policy was modified.", bulletList(blocked)),
+		}}
+	}
+
+	for _, policyName := range securityPolicyNames {
+		url := config.Server + "/configs/security/v1/tenant/default/networksecuritypolicies/" + policyName
+		err := updatePolicyWithRetry(ctx, config, url, func(body []byte) (interface{}, error) {
+			var current NetworkSecurityPolicy
+			if err := json.Unmarshal(body, &current); err != nil {
+				return nil, err
+			}
+			for i := range current.Spec.Rules {
+				if err := current.Spec.Rules[i].detach(kind, name); err != nil {
+					return nil, fmt.Errorf("rule %s: %w", ruleLabel(current.Spec.Rules[i].Name, i), err)
+				}
+			}
+			return current, nil
+		})
+		if err != nil {
+			return diag.Errorf("failed to detach %s %s from security policy %s: %v", kind, name, policyName, err)
+		}
+	}
+	for _, policyName := range natPolicyNames {
+		url := config.Server + "/configs/network/v1/tenant/default/natpolicies/" + policyName
+		err := updatePolicyWithRetry(ctx, config, url, func(body []byte) (interface{}, error) {
+			var current NATPolicy
+			if err := json.Unmarshal(body, &current); err != nil {
+				return nil, err
+			}
+			for i := range current.Spec.Rules {
+				if err := current.Spec.Rules[i].detach(name); err != nil {
+					return nil, fmt.Errorf("rule %s: %w", ruleLabel(current.Spec.Rules[i].Name, i), err)
+				}
+			}
+			return current, nil
+		})
+		if err != nil {
+			return diag.Errorf("failed to detach %s %s from NAT policy %s: %v", kind, name, policyName, err)
+		}
+	}
+	for _, ref := range refs {
+		log.Printf("[INFO] Detached %s %s from %s", kind, name, ref)
+	}
+
+	return nil
+}
+
+func bulletList(lines []string) string {
+	return "  - " + strings.Join(lines, "\n  - ")
+}
+
+// updatePolicyWithRetry reads a policy, lets update change it and writes it back. The policy is sent with the
+// resource version it was read at, so PSM rejects the write with a conflict if it was edited in the meantime instead
+// of overwriting that edit; the policy is then read and changed again.
+func updatePolicyWithRetry(ctx context.Context, config *Config, url string, update func(body []byte) (interface{}, error)) error {
+	for attempt := 1; ; attempt++ {
+		body, err := doNetworkRequest(ctx, config, "GET", url, nil)
+		if err != nil {
+			return err
+		}
+		payload, err := update(body)
+		if err != nil {
+			return err
+		}
+		jsonBytes, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "PUT", url, bytes.NewBuffer(jsonBytes))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.AddCookie(&http.Cookie{Name: "sid", Value: config.SID})
+
+		resp, err := config.Client().Do(req)
+		if err != nil {
+			return err
+		}
+		respBody, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		switch {
+		case resp.StatusCode == http.StatusOK:
+			return nil
+		case resp.StatusCode == http.StatusConflict && attempt < maxPolicyUpdateAttempts:
+			log.Printf("[DEBUG] Policy %s changed concurrently, retrying the update: %s", url, respBody)
+		default:
+			return fmt.Errorf("HTTP %d %s: %s", resp.StatusCode, resp.Status, respBody)
+		}
+	}
+}
+
+func (r *Rule) references(kind, name string) bool {
+	switch kind {
+	case referenceApp:
+		return containsString(r.Apps, name)
+	case referenceIPCollection:
+		return containsString(r.FromIPCollections, name) || containsString(r.ToIPCollections, name)
+	case referenceRuleProfile:
+		return r.RuleProfile == name
+	case referenceWorkloadGroup:
+		return containsString(r.FromWorkloadGroup, name) || containsString(r.ToWorkloadGroup, name)
+	}
+	return false
+}
+
+// detach removes the references to an object from a rule. Other selectors of the rule are kept as they are. An error
+// is returned, and the rule should not be written back, if the object was the only source, destination or app of the
+// rule, as the rule would then match any traffic.
+func (r *Rule) detach(kind, name string) error {
+	if !r.references(kind, name) {
+		return nil
+	}
+	switch kind {
+	case referenceApp:
+		r.Apps = removeString(r.Apps, name)
+		if len(r.Apps) == 0 && len(r.ProtoPorts) == 0 {
+			return fmt.Errorf("it is the only app of the rule")
+		}
+		return nil
+	case referenceRuleProfile:
+		r.RuleProfile = ""
+		return nil
+	}
+
+	from, to := &r.FromIPCollections, &r.ToIPCollections
+	if kind == referenceWorkloadGroup {
+		from, to = &r.FromWorkloadGroup, &r.ToWorkloadGroup
+	}
+	fromReferenced, toReferenced := containsString(*from, name), containsString(*to, name)
+	*from = removeString(*from, name)
+	*to = removeString(*to, name)
+	if fromReferenced && len(r.FromIPAddresses)+len(r.FromIPCollections)+len(r.FromWorkloadGroup) == 0 {
+		return fmt.Errorf("it is the only source of the rule")
+	}
+	if toReferenced && len(r.ToIPAddresses)+len(r.ToIPCollections)+len(r.ToWorkloadGroup) == 0 {
+		return fmt.Errorf("it is the only destination of the rule")
+	}
+	return nil
+}
+
+// natRuleAddresses returns the address collections of a NAT rule, in the order they are described in errors.
+func (r *NatRule) natRuleAddresses() []struct {
+	field      string
+	collection *AddressCollection
+} {
+	return []struct {
+		field      string
+		collection *AddressCollection
+	}{
+		{"source", r.Source},
+		{"destination", r.Destination},
+		{"translated source", r.TranslatedSource},
+		{"translated destination", r.TranslatedDestination},
+	}
+}
+
+// references reports whether a NAT rule references an IP collection, the only kind of object NAT rules refer to.
+func (r *NatRule) references(name string) bool {
+	for _, addresses := range r.natRuleAddresses() {
+		if addresses.collection != nil && containsString(addresses.collection.IPCollections, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// detach removes the references to an IP collection from a NAT rule. As for security rules, an error is returned if
+// the collection was the only entry of a source or destination.
+func (r *NatRule) detach(name string) error {
+	for _, addresses := range r.natRuleAddresses() {
+		collection := addresses.collection
+		if collection == nil || !containsString(collection.IPCollections, name) {
+			continue
+		}
+		collection.IPCollections = removeString(collection.IPCollections, name)
+		if len(collection.Addresses) == 0 && len(collection.IPCollections) == 0 && !collection.Any {
+			return fmt.Errorf("it is the only %s of the rule", addresses.field)
+		}
+	}
+	return nil
+}
+
+func listSecurityPolicies(ctx context.Context, config *Config) ([]NetworkSecurityPolicy, error) {
+	body, err := doNetworkRequest(ctx, config, "GET", config.Server+"/configs/security/v1/tenant/default/networksecuritypolicies", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list security policies: %w", err)
+	}
+
+	var list struct {
+		Items []NetworkSecurityPolicy `json:"items"`
+	}
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+func listNATPolicies(ctx context.Context, config *Config) ([]NATPolicy, error) {
+	body, err := doNetworkRequest(ctx, config, "GET", config.Server+"/configs/network/v1/tenant/default/natpolicies", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list NAT policies: %w", err)
+	}
+
+	var list struct {
+		Items []NATPolicy `json:"items"`
+	}
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
@@ -0,0 +1,409 @@
+package psm
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestRuleReferences(t *testing.T) {
+	rule := Rule{
+		Apps:              []string{"SSH"},
+		FromIPCollections: []string{"office"},
+		ToWorkloadGroup:   []string{"web"},
+		RuleProfile:       "strict",
+	}
+
+	tests := []struct {
+		kind, name string
+		want       bool
+	}{
+		{referenceApp, "SSH", true},
+		{referenceApp, "HTTPS", false},
+		{referenceIPCollection, "office", true},
+		{referenceIPCollection, "web", false},
+		{referenceWorkloadGroup, "web", true},
+		{referenceWorkloadGroup, "office", false},
+		{referenceRuleProfile, "strict", true},
+		{referenceRuleProfile, "", false},
+		{"unknown kind", "SSH", false},
+	}
+
+	for _, tt := range tests {
+		if got := rule.references(tt.kind, tt.name); got != tt.want {
+			t.Errorf("references(%q, %q) = %t, want %t", tt.kind, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRuleDetach(t *testing.T) {
+	tests := []struct {
+		name      string
+		rule      Rule
+		kind, ref string
+		want      Rule
+		wantErr   string
+	}{
+		{
+			name: "one of several apps",
+			rule: Rule{Apps: []string{"SSH", "HTTPS"}},
+			kind: referenceApp, ref: "SSH",
+			want: Rule{Apps: []string{"HTTPS"}},
+		},
+		{
+			name: "only app of a rule with proto_ports",
+			rule: Rule{Apps: []string{"SSH"}, ProtoPorts: []ProtoPort{{Protocol: "tcp", Ports: "22"}}},
+			kind: referenceApp, ref: "SSH",
+			want: Rule{ProtoPorts: []ProtoPort{{Protocol: "tcp", Ports: "22"}}},
+		},
+		{
+			name: "only app",
+			rule: Rule{Apps: []string{"SSH"}},
+			kind: referenceApp, ref: "SSH",
+			wantErr: "it is the only app of the rule",
+		},
+		{
+			name: "rule profile",
+			rule: Rule{RuleProfile: "strict", Apps: []string{"SSH"}},
+			kind: referenceRuleProfile, ref: "strict",
+			want: Rule{Apps: []string{"SSH"}},
+		},
+		{
+			name: "IP collection as source and destination",
+			rule: Rule{
+				FromIPCollections: []string{"office", "lab"},
+				ToIPCollections:   []string{"office"},
+				ToIPAddresses:     []string{"10.0.0.1"},
+			},
+			kind: referenceIPCollection, ref: "office",
+			want: Rule{FromIPCollections: []string{"lab"}, ToIPAddresses: []string{"10.0.0.1"}},
+		},
+		{
+			name: "only source IP collection",
+			rule: Rule{FromIPCollections: []string{"office"}, ToIPAddresses: []string{"any"}},
+			kind: referenceIPCollection, ref: "office",
+			wantErr: "it is the only source of the rule",
+		},
+		{
+			name: "only destination workload group",
+			rule: Rule{FromIPAddresses: []string{"any"}, ToWorkloadGroup: []string{"web"}},
+			kind: referenceWorkloadGroup, ref: "web",
+			wantErr: "it is the only destination of the rule",
+		},
+		{
+			name: "workload group next to an IP collection",
+			rule: Rule{FromWorkloadGroup: []string{"web"}, FromIPCollections: []string{"office"}, ToIPAddresses: []string{"any"}},
+			kind: referenceWorkloadGroup, ref: "web",
+			want: Rule{FromIPCollections: []string{"office"}, ToIPAddresses: []string{"any"}},
+		},
+		{
+			name: "workload group of the same name as an IP collection",
+			rule: Rule{FromWorkloadGroup: []string{"web"}, FromIPCollections: []string{"web"}},
+			kind: referenceIPCollection, ref: "web",
+			want: Rule{FromWorkloadGroup: []string{"web"}},
+		},
+		{
+			name: "not referenced",
+			rule: Rule{Apps: []string{"SSH"}},
+			kind: referenceApp, ref: "HTTPS",
+			want: Rule{Apps: []string{"SSH"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := tt.rule
+			err := rule.detach(tt.kind, tt.ref)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("detach() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("detach() error = %v", err)
+			}
+			if !equalRuleReferences(rule, tt.want) {
+				t.Errorf("detach() = %+v, want %+v", rule, tt.want)
+			}
+		})
+	}
+}
+
+// equalRuleReferences compares the selectors of two rules, treating empty and nil lists alike.
+func equalRuleReferences(a, b Rule) bool {
+	return slices.Equal(a.Apps, b.Apps) &&
+		slices.Equal(a.ProtoPorts, b.ProtoPorts) &&
+		a.RuleProfile == b.RuleProfile &&
+		slices.Equal(a.FromIPAddresses, b.FromIPAddresses) &&
+		slices.Equal(a.ToIPAddresses, b.ToIPAddresses) &&
+		slices.Equal(a.FromIPCollections, b.FromIPCollections) &&
+		slices.Equal(a.ToIPCollections, b.ToIPCollections) &&
+		slices.Equal(a.FromWorkloadGroup, b.FromWorkloadGroup) &&
+		slices.Equal(a.ToWorkloadGroup, b.ToWorkloadGroup)
+}
+
+func TestNatRuleDetach(t *testing.T) {
+	tests := []struct {
+		name           string
+		rule           NatRule
+		wantReferenced bool
+		wantErr        string
+		want           NatRule
+	}{
+		{
+			name: "one of several collections",
+			rule: NatRule{
+				Source:      &AddressCollection{IPCollections: []string{"office", "lab"}},
+				Destination: &AddressCollection{Addresses: []string{"10.0.0.1"}},
+			},
+			wantReferenced: true,
+			want: NatRule{
+				Source:      &AddressCollection{IPCollections: []string{"lab"}},
+				Destination: &AddressCollection{Addresses: []string{"10.0.0.1"}},
+			},
+		},
+		{
+			name:           "next to addresses",
+			rule:           NatRule{TranslatedSource: &AddressCollection{Addresses: []string{"192.0.2.1"}, IPCollections: []string{"office"}}},
+			wantReferenced: true,
+			want:           NatRule{TranslatedSource: &AddressCollection{Addresses: []string{"192.0.2.1"}}},
+		},
+		{
+			name:           "next to any",
+			rule:           NatRule{Destination: &AddressCollection{Any: true, IPCollections: []string{"office"}}},
+			wantReferenced: true,
+			want:           NatRule{Destination: &AddressCollection{Any: true}},
+		},
+		{
+			name:           "only source",
+			rule:           NatRule{Source: &AddressCollection{IPCollections: []string{"office"}}},
+			wantReferenced: true,
+			wantErr:        "it is the only source of the rule",
+		},
+		{
+			name: "only translated destination",
+			rule: NatRule{
+				Source:                &AddressCollection{Any: true},
+				TranslatedDestination: &AddressCollection{IPCollections: []string{"office"}},
+			},
+			wantReferenced: true,
+			wantErr:        "it is the only translated destination of the rule",
+		},
+		{
+			name: "not referenced",
+			rule: NatRule{Source: &AddressCollection{IPCollections: []string{"lab"}}},
+			want: NatRule{Source: &AddressCollection{IPCollections: []string{"lab"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := tt.rule
+			if got := rule.references("office"); got != tt.wantReferenced {
+				t.Errorf("references() = %t, want %t", got, tt.wantReferenced)
+			}
+
+			err := rule.detach("office")
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("detach() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("detach() error = %v", err)
+			}
+			for i, got := range rule.natRuleAddresses() {
+				want := tt.want.natRuleAddresses()[i]
+				if (got.collection == nil) != (want.collection == nil) {
+					t.Errorf("%s = %+v, want %+v", got.field, got.collection, want.collection)
+					continue
+				}
+				if got.collection != nil && !equalAddressCollections(*got.collection, *want.collection) {
+					t.Errorf("%s = %+v, want %+v", got.field, *got.collection, *want.collection)
+				}
+			}
+		})
+	}
+}
+
+func equalAddressCollections(a, b AddressCollection) bool {
+	return a.Any == b.Any && slices.Equal(a.Addresses, b.Addresses) && slices.Equal(a.IPCollections, b.IPCollections)
+}
+
+// policyServer is a fake PSM holding security and NAT policies, which records the policies written back. The first
+// conflicts writes of a policy are rejected with 409 Conflict, as PSM does when the policy was edited concurrently.
+type policyServer struct {
+	mu          sync.Mutex
+	policies    map[string]string
+	natPolicies map[string]string
+	conflicts   int
+	written     map[string][]string
+}
+
+func (s *policyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	const (
+		securityPath = "/configs/security/v1/tenant/default/networksecuritypolicies"
+		natPath      = "/configs/network/v1/tenant/default/natpolicies"
+	)
+	var items map[string]string
+	var name string
+	switch {
+	case strings.HasPrefix(r.URL.Path, securityPath):
+		items, name = s.policies, strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, securityPath), "/")
+	case strings.HasPrefix(r.URL.Path, natPath):
+		items, name = s.natPolicies, strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, natPath), "/")
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && name == "":
+		list := make([]json.RawMessage, 0, len(items))
+		for _, body := range items {
+			list = append(list, json.RawMessage(body))
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"items": list})
+	case r.Method == http.MethodGet && items[name] != "":
+		w.Write([]byte(items[name]))
+	case r.Method == http.MethodPut && items[name] != "":
+		body, _ := io.ReadAll(r.Body)
+		if s.conflicts > 0 {
+			s.conflicts--
+			http.Error(w, `{"message": "resource version mismatch"}`, http.StatusConflict)
+			return
+		}
+		items[name] = string(body)
+		s.written[name] = append(s.written[name], string(body))
+		w.Write(body)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func newPolicyServer(t *testing.T, conflicts int) (*policyServer, *Config) {
+	s := &policyServer{
+		policies: map[string]string{
+			"allow-web": `{"meta": {"name": "allow-web", "resource-version": "7"}, "spec": {"rules": [
+				{"name": "web", "action": "permit", "from-ipcollections": ["office", "lab"], "to-ip-addresses": ["10.0.0.1"], "apps": ["HTTP", "HTTPS"]},
+				{"name": "ssh", "action": "permit", "from-ip-addresses": ["any"], "to-ip-addresses": ["10.0.0.2"], "apps": ["SSH"]}
+			]}}`,
+			"deny-lab": `{"meta": {"name": "deny-lab", "resource-version": "3"}, "spec": {"rules": [
+				{"action": "deny", "from-ipcollections": ["lab"], "to-ip-addresses": ["any"]}
+			]}}`,
+		},
+		natPolicies: map[string]string{
+			"outbound": `{"meta": {"name": "outbound"}, "spec": {"rules": [
+				{"name": "snat", "type": "static", "source": {"ipcollections": ["office"], "addresses": ["10.1.0.0/16"]}}
+			]}}`,
+		},
+		conflicts: conflicts,
+		written:   make(map[string][]string),
+	}
+	server := httptest.NewServer(s)
+	t.Cleanup(server.Close)
+	return s, &Config{Server: server.URL}
+}
+
+func TestCheckPolicyReferences(t *testing.T) {
+	tests := []struct {
+		name         string
+		kind, ref    string
+		forceDetach  bool
+		conflicts    int
+		wantErr      string
+		wantDetail   []string
+		wantWritten  []string
+		wantNotInPUT string
+	}{
+		{
+			name: "not referenced",
+			kind: referenceApp, ref: "DNS",
+		},
+		{
+			name: "referenced without force_detach",
+			kind: referenceIPCollection, ref: "office",
+			wantErr:    "ip collection office is still referenced by 2 policy rule(s)",
+			wantDetail: []string{"security policy allow-web, rule web", "NAT policy outbound, rule snat", "force_detach = true"},
+		},
+		{
+			name: "detached from security and NAT rules",
+			kind: referenceIPCollection, ref: "office", forceDetach: true,
+			wantWritten:  []string{"allow-web", "outbound"},
+			wantNotInPUT: `"office"`,
+		},
+		{
+			name: "only app of a rule",
+			kind: referenceApp, ref: "SSH", forceDetach: true,
+			wantErr:    "app SSH cannot be detached from 1 policy rule(s)",
+			wantDetail: []string{"security policy allow-web, rule ssh: it is the only app of the rule"},
+		},
+		{
+			name: "only source of an unnamed rule",
+			kind: referenceIPCollection, ref: "lab", forceDetach: true,
+			wantErr:    "ip collection lab cannot be detached from 1 policy rule(s)",
+			wantDetail: []string{"security policy deny-lab, rule #1: it is the only source of the rule"},
+		},
+		{
+			name: "retried on conflict",
+			kind: referenceApp, ref: "HTTPS", forceDetach: true, conflicts: 2,
+			wantWritten:  []string{"allow-web"},
+			wantNotInPUT: `"HTTPS"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, config := newPolicyServer(t, tt.conflicts)
+			d := schema.TestResourceDataRaw(t, map[string]*schema.Schema{"force_detach": forceDetachSchema()},
+				map[string]interface{}{"force_detach": tt.forceDetach})
+
+			diags := checkPolicyReferences(context.Background(), d, config, tt.kind, tt.ref)
+			if tt.wantErr != "" {
+				if !diags.HasError() || !strings.Contains(diags[0].Summary+diags[0].Detail, tt.wantErr) {
+					t.Fatalf("checkPolicyReferences() = %v, want %q", diags, tt.wantErr)
+				}
+				for _, want := range tt.wantDetail {
+					if !strings.Contains(diags[0].Detail, want) {
+						t.Errorf("detail does not contain %q:\n%s", want, diags[0].Detail)
+					}
+				}
+				if len(server.written) > 0 {
+					t.Errorf("policies %v were written although the references could not be removed", server.written)
+				}
+				return
+			}
+			if diags.HasError() {
+				t.Fatalf("checkPolicyReferences() = %v", diags)
+			}
+
+			var written []string
+			for name, bodies := range server.written {
+				written = append(written, name)
+				if len(bodies) != 1 {
+					t.Errorf("policy %s written %d times, want once", name, len(bodies))
+				}
+				if strings.Contains(bodies[0], tt.wantNotInPUT) {
+					t.Errorf("policy %s still references %s: %s", name, tt.wantNotInPUT, bodies[0])
+				}
+			}
+			slices.Sort(written)
+			if !slices.Equal(written, tt.wantWritten) {
+				t.Errorf("written policies = %q, want %q", written, tt.wantWritten)
+			}
+		})
+	}
+}
@@ -38,6 +38,20 @@ func Provider() *schema.Provider {
 			"psm_user_preferences":     resourcePSMUserPreferences(),
 			"psm_hosts":                resourceHosts(),
 			"psm_mirror_session":       resourceMirrorSession(),
+			"psm_bgp_config":           resourceBGPConfig(),
+			"psm_ipam_policy":          resourceIPAMPolicy(),
+			"psm_workload_labels":      resourceWorkloadLabels(),
+			"psm_workload_set":         resourceWorkloadSet(),
+		},
+		DataSourcesMap: map[string]*schema.Resource{
+			"psm_security_policy_stats": dataSourceSecurityPolicyStats(),
+			"psm_vrf_routes":            dataSourceVRFRoutes(),
+			"psm_bgp_neighbors":         dataSourceBGPNeighbors(),
+			"psm_ipcollection_expanded": dataSourceIPCollectionExpanded(),
+			"psm_workloadgroup_members": dataSourceWorkloadGroupMembers(),
+			"psm_learned_endpoints":     dataSourceLearnedEndpoints(),
+			"psm_apps_catalog":          dataSourceAppsCatalog(),
+			"psm_app":                   dataSourceApp(),
 		},
 		Schema: map[string]*schema.Schema{
 			"user": {
@@ -0,0 +1,9 @@
+package psm
+
+import "testing"
+
+func TestProvider(t *testing.T) {
+	if err := Provider().InternalValidate(); err != nil {
+		t.Fatalf("InternalValidate() error = %v", err)
+	}
+}
@@ -48,6 +48,7 @@ func resourceRuleProfile() *schema.Resource {
 					"disable",
 				}, false),
 			},
+			"force_detach": forceDetachSchema(),
 		},
 	}
 }
@@ -196,6 +197,10 @@ func resourceRuleProfileDelete(ctx context.Context, d *schema.ResourceData, m in
 	config := m.(*Config)
 	client := config.Client()
 
+	if diags := checkPolicyReferences(ctx, d, config, referenceRuleProfile, d.Id()); diags.HasError() {
+		return diags
+	}
+
 	url := fmt.Sprintf("%s/configs/security/v1/tenant/default/ruleProfiles/%s", config.Server, d.Id())
 
 	req, err := http.NewRequestWithContext(ctx, "DELETE", url, nil)
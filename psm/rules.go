@@ -8,6 +8,8 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"sort"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -32,6 +34,10 @@ func resourceRules() *schema.Resource {
 		ReadContext:   resourceRulesRead,
 		UpdateContext: resourceRulesUpdate,
 		DeleteContext: resourceRulesDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceRulesImport,
+		},
+		CustomizeDiff: resourceRulesCustomizeDiff,
 		Schema: map[string]*schema.Schema{
 			"policy_name": {
 				Type:     schema.TypeString,
@@ -45,10 +51,19 @@ func resourceRules() *schema.Resource {
 				ForceNew: true,
 			},
 			"policy_distribution_target": {
-				Type:     schema.TypeString,
-				Optional: true,
-				Default:  "default",
-				ForceNew: true,
+				Type:          schema.TypeString,
+				Optional:      true,
+				Computed:      true,
+				Deprecated:    "Use policy_distribution_targets instead",
+				ConflictsWith: []string{"policy_distribution_targets"},
+			},
+			"policy_distribution_targets": {
+				Type:          schema.TypeSet,
+				Optional:      true,
+				Computed:      true,
+				Elem:          &schema.Schema{Type: schema.TypeString},
+				ConflictsWith: []string{"policy_distribution_target"},
+				Description:   "Policy distribution targets the policy is pushed to, defaults to the default target",
 			},
 			"address_family": {
 				Type:         schema.TypeString,
@@ -57,6 +72,47 @@ func resourceRules() *schema.Resource {
 				ValidateFunc: validateAddressFamily,
 				Description:  "Address family for the security policy, must be either 'IPv4' or 'IPv6'",
 			},
+			"wait_for_propagation": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Wait until every DSE in the policy distribution target has been programmed with the policy",
+			},
+			"propagation_timeout": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "5m",
+				ValidateFunc: validateDuration,
+				Description:  "How long to wait for propagation when wait_for_propagation is set, e.g. 5m",
+			},
+			"propagation_status": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"generation_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"updated": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"pending": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"min_version": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"status": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
 			"meta": {
 				Type:     schema.TypeSet,
 				Computed: true,
@@ -429,7 +485,7 @@ func resourceRulesCreate(ctx context.Context, d *schema.ResourceData, m interfac
 		Spec: Spec{
 			AttachTenant:              true,
 			AddressFamily:             addressFamily,
-			PolicyDistributionTargets: []string{d.Get("policy_distribution_target").(string)},
+			PolicyDistributionTargets: expandPolicyDistributionTargets(d),
 			Rules:                     []Rule{},
 		},
 	}
@@ -524,6 +580,7 @@ func resourceRulesCreate(ctx context.Context, d *schema.ResourceData, m interfac
 	d.SetId(*responsePolicy.Meta.UUID)
 	d.Set("policy_name", responsePolicy.Meta.Name)
 	d.Set("tenant", responsePolicy.Meta.Tenant)
+	setPolicyDistributionTargets(d, responsePolicy.Spec.PolicyDistributionTargets)
 	d.Set("address_family", responsePolicy.Spec.AddressFamily)
 
 	rules := make([]interface{}, len(responsePolicy.Spec.Rules))
@@ -565,7 +622,7 @@ func resourceRulesCreate(ctx context.Context, d *schema.ResourceData, m interfac
 	}}); err != nil {
 		return diag.FromErr(err)
 	}
-	return nil
+	return waitForRulesPropagation(ctx, d, m, responsePolicy)
 }
 
 func resourceRulesRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
@@ -608,6 +665,7 @@ func resourceRulesRead(ctx context.Context, d *schema.ResourceData, m interface{
 	d.SetId(*responsePolicy.Meta.UUID)
 	d.Set("policy_name", responsePolicy.Meta.Name)
 	d.Set("tenant", responsePolicy.Meta.Tenant)
+	setPolicyDistributionTargets(d, responsePolicy.Spec.PolicyDistributionTargets)
 	d.Set("address_family", responsePolicy.Spec.AddressFamily)
 
 	rules := make([]map[string]interface{}, len(responsePolicy.Spec.Rules))
@@ -662,6 +720,9 @@ func resourceRulesRead(ctx context.Context, d *schema.ResourceData, m interface{
 	}}); err != nil {
 		return diag.FromErr(err)
 	}
+	if err := d.Set("propagation_status", flattenPropagationStatus(responsePolicy.Status.PropagationStatus)); err != nil {
+		return diag.FromErr(err)
+	}
 	return nil
 }
 
@@ -696,7 +757,7 @@ func resourceRulesUpdate(ctx context.Context, d *schema.ResourceData, m interfac
 		Spec: Spec{
 			AttachTenant:              true,
 			AddressFamily:             addressFamily,
-			PolicyDistributionTargets: []string{d.Get("policy_distribution_target").(string)},
+			PolicyDistributionTargets: expandPolicyDistributionTargets(d),
 			Rules:                     []Rule{},
 		},
 	}
@@ -789,6 +850,7 @@ func resourceRulesUpdate(ctx context.Context, d *schema.ResourceData, m interfac
 	d.SetId(*responsePolicy.Meta.UUID)
 	d.Set("policy_name", responsePolicy.Meta.Name)
 	d.Set("tenant", responsePolicy.Meta.Tenant)
+	setPolicyDistributionTargets(d, responsePolicy.Spec.PolicyDistributionTargets)
 
 	rules := make([]interface{}, len(responsePolicy.Spec.Rules))
 	for i, rule := range responsePolicy.Spec.Rules {
@@ -829,7 +891,7 @@ func resourceRulesUpdate(ctx context.Context, d *schema.ResourceData, m interfac
 	}}); err != nil {
 		return diag.FromErr(err)
 	}
-	return nil
+	return waitForRulesPropagation(ctx, d, m, responsePolicy)
 }
 
 func resourceRulesDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
@@ -872,3 +934,184 @@ func resourceRulesDelete(ctx context.Context, d *schema.ResourceData, m interfac
 
 	return nil
 }
+
+func resourceRulesImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	config := m.(*Config)
+
+	// The ID passed will be the name of the Security Policy
+	policy, err := getNetworkSecurityPolicy(ctx, config, d.Id())
+	if err != nil {
+		return nil, fmt.Errorf("error importing Security Policy: %v", err)
+	}
+
+	d.Set("policy_name", policy.Meta.Name)
+	d.Set("wait_for_propagation", false)
+	d.Set("propagation_timeout", "5m")
+	setPolicyDistributionTargets(d, policy.Spec.PolicyDistributionTargets)
+
+	// Populate the configurable rule blocks so that an imported policy matches its HCL definition
+	rules := make([]interface{}, len(policy.Spec.Rules))
+	for i, rule := range policy.Spec.Rules {
+		ruleMap := map[string]interface{}{
+			"rule_name":           rule.Name,
+			"action":              rule.Action,
+			"description":         rule.Description,
+			"apps":                rule.Apps,
+			"disable":             rule.Disable,
+			"from_ip_collections": rule.FromIPCollections,
+			"to_ip_collections":   rule.ToIPCollections,
+			"from_ip_addresses":   rule.FromIPAddresses,
+			"to_ip_addresses":     rule.ToIPAddresses,
+			"from_workloadgroups": rule.FromWorkloadGroup,
+			"to_workloadgroups":   rule.ToWorkloadGroup,
+			"rule_profile":        rule.RuleProfile,
+			"labels":              rule.Labels,
+		}
+
+		protoPorts := make([]interface{}, len(rule.ProtoPorts))
+		for j, pp := range rule.ProtoPorts {
+			protoPorts[j] = map[string]interface{}{
+				"protocol": pp.Protocol,
+				"ports":    pp.Ports,
+			}
+		}
+		ruleMap["proto_ports"] = protoPorts
+
+		rules[i] = ruleMap
+	}
+	if err := d.Set("rule", rules); err != nil {
+		return nil, err
+	}
+
+	diags := resourceRulesRead(ctx, d, m)
+	if diags.HasError() {
+		return nil, fmt.Errorf("failed to read imported Security Policy: %v", diags)
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
+// waitForRulesPropagation blocks until every DSE has been programmed with the generation of the policy returned by
+// a create or update, when wait_for_propagation is set, and records the last observed propagation status.
+func waitForRulesPropagation(ctx context.Context, d *schema.ResourceData, m interface{}, policy *NetworkSecurityPolicy) diag.Diagnostics {
+	if err := d.Set("propagation_status", flattenPropagationStatus(policy.Status.PropagationStatus)); err != nil {
+		return diag.FromErr(err)
+	}
+	if !d.Get("wait_for_propagation").(bool) {
+		return nil
+	}
+
+	config := m.(*Config)
+	generationID := ""
+	if policy.Meta.GenerationID != nil {
+		generationID = *policy.Meta.GenerationID
+	}
+	propagationTimeout, _ := time.ParseDuration(d.Get("propagation_timeout").(string))
+
+	timeout := time.After(propagationTimeout)
+	ticker := time.NewTicker(rulesPropagationPollInterval)
+	defer ticker.Stop()
+
+	status := policy.Status.PropagationStatus
+	for {
+		select {
+		case <-timeout:
+			return diag.Errorf("timeout waiting for Security Policy %s generation %s to propagate: %d updated, %d pending, min version %q, status %q",
+				policy.Meta.Name, generationID, status.Updated, status.Pending, status.MinVersion, status.Status)
+		case <-ticker.C:
+			current, err := getNetworkSecurityPolicy(ctx, config, policy.Meta.Name)
+			if err != nil {
+				return diag.FromErr(err)
+			}
+			status = current.Status.PropagationStatus
+			if err := d.Set("propagation_status", flattenPropagationStatus(status)); err != nil {
+				return diag.FromErr(err)
+			}
+
+			if status.GenerationID == generationID && status.Pending == 0 {
+				log.Printf("[DEBUG] Security Policy %s generation %s propagated to %d DSEs", policy.Meta.Name, generationID, status.Updated)
+				return nil
+			}
+			log.Printf("[DEBUG] Security Policy %s propagation in progress: generation %s, %d updated, %d pending",
+				policy.Meta.Name, status.GenerationID, status.Updated, status.Pending)
+		case <-ctx.Done():
+			return diag.FromErr(ctx.Err())
+		}
+	}
+}
+
+// rulesPropagationPollInterval is how often the propagation status is read while waiting for it.
+var rulesPropagationPollInterval = 5 * time.Second
+
+func flattenPropagationStatus(status PropagationStatus) []interface{} {
+	return []interface{}{map[string]interface{}{
+		"generation_id": status.GenerationID,
+		"updated":       status.Updated,
+		"pending":       status.Pending,
+		"min_version":   status.MinVersion,
+		"status":        status.Status,
+	}}
+}
+
+// resourceRulesCustomizeDiff plans the policy distribution targets from whichever of policy_distribution_targets or
+// the deprecated policy_distribution_target is configured. Both are computed, so without this removing them from the
+// configuration would keep the previous targets instead of returning to the default target.
+func resourceRulesCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	raw := d.GetRawConfig()
+	if raw.IsNull() || !raw.IsKnown() {
+		return nil
+	}
+	targetsRaw, targetRaw := raw.GetAttr("policy_distribution_targets"), raw.GetAttr("policy_distribution_target")
+	if !targetsRaw.IsWhollyKnown() || !targetRaw.IsKnown() {
+		return nil
+	}
+
+	if !targetsRaw.IsNull() && targetsRaw.LengthInt() > 0 {
+		targets := ExpandStringSet(d.Get("policy_distribution_targets").(*schema.Set))
+		sort.Strings(targets)
+		return d.SetNew("policy_distribution_target", targets[0])
+	}
+
+	target := "default"
+	if !targetRaw.IsNull() && targetRaw.AsString() != "" {
+		target = targetRaw.AsString()
+	}
+	if err := d.SetNew("policy_distribution_targets", []interface{}{target}); err != nil {
+		return err
+	}
+	return d.SetNew("policy_distribution_target", target)
+}
+
+// expandPolicyDistributionTargets returns the targets from whichever of policy_distribution_targets or the
+// deprecated policy_distribution_target is configured. Both are computed, so the raw configuration decides which
+// one the user set; when neither is set the policy uses the default target, as planned by resourceRulesCustomizeDiff.
+func expandPolicyDistributionTargets(d *schema.ResourceData) []string {
+	raw := d.GetRawConfig()
+	if !raw.IsNull() {
+		if v := raw.GetAttr("policy_distribution_targets"); !v.IsNull() && v.LengthInt() > 0 {
+			targets := ExpandStringSet(d.Get("policy_distribution_targets").(*schema.Set))
+			sort.Strings(targets)
+			return targets
+		}
+		if v := raw.GetAttr("policy_distribution_target"); !v.IsNull() && v.AsString() != "" {
+			return []string{v.AsString()}
+		}
+	}
+
+	if v, ok := d.GetOk("policy_distribution_targets"); ok && v.(*schema.Set).Len() > 0 {
+		targets := ExpandStringSet(v.(*schema.Set))
+		sort.Strings(targets)
+		return targets
+	}
+	if v, ok := d.GetOk("policy_distribution_target"); ok {
+		return []string{v.(string)}
+	}
+	return []string{"default"}
+}
+
+func setPolicyDistributionTargets(d *schema.ResourceData, targets []string) {
+	d.Set("policy_distribution_targets", targets)
+	if len(targets) > 0 {
+		d.Set("policy_distribution_target", targets[0])
+	}
+}
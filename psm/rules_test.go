@@ -0,0 +1,144 @@
+package psm
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestWaitForRulesPropagation(t *testing.T) {
+	defer func(interval time.Duration) { rulesPropagationPollInterval = interval }(rulesPropagationPollInterval)
+	rulesPropagationPollInterval = 10 * time.Millisecond
+
+	const path = "/configs/security/v1/tenant/default/networksecuritypolicies/web"
+
+	tests := []struct {
+		name        string
+		wait        bool
+		current     string
+		wantErr     string
+		wantPending int
+	}{
+		{
+			name:        "not waiting",
+			wait:        false,
+			wantPending: 2,
+		},
+		{
+			name:        "propagated",
+			wait:        true,
+			current:     `{"meta": {"name": "web"}, "status": {"propagation-status": {"generation-id": "7", "updated": 3, "pending": 0}}}`,
+			wantPending: 0,
+		},
+		{
+			name:        "earlier generation fully propagated",
+			wait:        true,
+			current:     `{"meta": {"name": "web"}, "status": {"propagation-status": {"generation-id": "6", "updated": 3, "pending": 0}}}`,
+			wantErr:     "timeout waiting for Security Policy web generation 7",
+			wantPending: 0,
+		},
+		{
+			name:        "still pending",
+			wait:        true,
+			current:     `{"meta": {"name": "web"}, "status": {"propagation-status": {"generation-id": "7", "updated": 1, "pending": 2}}}`,
+			wantErr:     "1 updated, 2 pending",
+			wantPending: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := newTestServer(t, map[string]string{path: tt.current})
+			d := resourceRules().TestResourceData()
+			d.Set("wait_for_propagation", tt.wait)
+			d.Set("propagation_timeout", "100ms")
+
+			policy := &NetworkSecurityPolicy{}
+			policy.Meta.Name = "web"
+			policy.Meta.GenerationID = stringPtr("7")
+			policy.Status.PropagationStatus = PropagationStatus{GenerationID: "6", Updated: 1, Pending: 2}
+
+			diags := waitForRulesPropagation(context.Background(), d, config, policy)
+			switch {
+			case tt.wantErr == "" && diags.HasError():
+				t.Errorf("waitForRulesPropagation() = %v, want no error", diags)
+			case tt.wantErr != "" && (!diags.HasError() || !strings.Contains(diags[0].Summary, tt.wantErr)):
+				t.Errorf("waitForRulesPropagation() = %v, want error containing %q", diags, tt.wantErr)
+			}
+			if got := d.Get("propagation_status.0.pending").(int); got != tt.wantPending {
+				t.Errorf("propagation_status.0.pending = %d, want %d", got, tt.wantPending)
+			}
+		})
+	}
+}
+
+func TestResourceRulesPolicyDistributionTargetsPlan(t *testing.T) {
+	state := map[string]string{
+		"id":                            "web",
+		"policy_name":                   "web",
+		"address_family":                "IPv4",
+		"wait_for_propagation":          "false",
+		"propagation_timeout":           "5m",
+		"policy_distribution_target":    "dc1",
+		"policy_distribution_targets.#": "2",
+		"policy_distribution_targets." + strconv.Itoa(hashString("dc1")): "dc1",
+		"policy_distribution_targets." + strconv.Itoa(hashString("dc2")): "dc2",
+	}
+
+	tests := []struct {
+		name        string
+		config      map[string]interface{}
+		wantTargets []string
+		wantTarget  string
+	}{
+		{
+			name:        "unset returns to the default target",
+			config:      map[string]interface{}{"policy_name": "web"},
+			wantTargets: []string{"default"},
+			wantTarget:  "default",
+		},
+		{
+			name:        "unchanged list",
+			config:      map[string]interface{}{"policy_name": "web", "policy_distribution_targets": []interface{}{"dc2", "dc1"}},
+			wantTargets: []string{"dc1", "dc2"},
+			wantTarget:  "dc1",
+		},
+		{
+			name:        "changed list",
+			config:      map[string]interface{}{"policy_name": "web", "policy_distribution_targets": []interface{}{"dc3", "dc2"}},
+			wantTargets: []string{"dc2", "dc3"},
+			wantTarget:  "dc2",
+		},
+		{
+			name:        "deprecated single target",
+			config:      map[string]interface{}{"policy_name": "web", "policy_distribution_target": "dc3"},
+			wantTargets: []string{"dc3"},
+			wantTarget:  "dc3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff, err := testPlan(t, resourceRules(), state, tt.config, nil)
+			if err != nil {
+				t.Fatalf("plan error = %v", err)
+			}
+			d := plannedData(t, resourceRules(), state, diff)
+
+			got := ExpandStringSet(d.Get("policy_distribution_targets").(*schema.Set))
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.wantTargets) {
+				t.Errorf("planned policy_distribution_targets = %q, want %q", got, tt.wantTargets)
+			}
+			if got := d.Get("policy_distribution_target").(string); got != tt.wantTarget {
+				t.Errorf("planned policy_distribution_target = %q, want %q", got, tt.wantTarget)
+			}
+		})
+	}
+}
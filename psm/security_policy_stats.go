@@ -0,0 +1,365 @@
+package psm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// ruleMetricsFields are the RuleMetrics counters reported by every DSE for each rule, in the order they are queried.
+var ruleMetricsFields = []string{"TotalHits", "TcpHits", "UdpHits", "IcmpHits", "OtherHits"}
+
+func dataSourceSecurityPolicyStats() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceSecurityPolicyStatsRead,
+		Schema: map[string]*schema.Schema{
+			"policy_name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Name of the network security policy to report statistics for",
+			},
+			"lookback": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "24h",
+				Description:  "How far back to query rule metrics, as a Go duration such as 24h or 168h",
+				ValidateFunc: validateDuration,
+			},
+			"rules": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"rule_name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"rule_index": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"rule_hash": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"action": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"total_hits": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"tcp_hits": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"udp_hits": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"icmp_hits": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"other_hits": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"last_hit": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"metrics_available": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+					},
+				},
+			},
+			"hit_counts": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeInt},
+				Description: "Total hits keyed by rule_name, for rules that have a unique name and metrics",
+			},
+		},
+	}
+}
+
+type MetricsQueryRequest struct {
+	Tenant  string         `json:"tenant"`
+	Queries []MetricsQuery `json:"queries"`
+}
+
+type MetricsQuery struct {
+	Kind         string           `json:"kind"`
+	Selector     *MetricsSelector `json:"selector,omitempty"`
+	Fields       []string         `json:"fields"`
+	Function     string           `json:"function"`
+	GroupByField string           `json:"group-by-field,omitempty"`
+	SortOrder    string           `json:"sort-order"`
+	StartTime    string           `json:"start-time"`
+	EndTime      string           `json:"end-time"`
+}
+
+type MetricsSelector struct {
+	Requirements []Requirement `json:"requirements"`
+}
+
+type MetricsQueryResponse struct {
+	Results []struct {
+		Series []MetricsSeries `json:"series"`
+	} `json:"results"`
+}
+
+type MetricsSeries struct {
+	Name    string            `json:"name"`
+	Tags    map[string]string `json:"tags"`
+	Columns []string          `json:"columns"`
+	Values  [][]interface{}   `json:"values"`
+}
+
+// ruleHits accumulates the counters of one rule across all reporting DSEs.
+type ruleHits struct {
+	counters map[string]int64
+	lastHit  time.Time
+}
+
+func dataSourceSecurityPolicyStatsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	config := m.(*Config)
+	client := config.Client()
+
+	policyName := d.Get("policy_name").(string)
+	lookback, _ := time.ParseDuration(d.Get("lookback").(string))
+
+	policy, err := getNetworkSecurityPolicy(ctx, config, policyName)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// PSM reports rule metrics per rule hash, which is only present in the policy status once the policy is propagated.
+	// Rules without a hash are not queried, as a query for an empty hash would report them as never hit.
+	end := time.Now().UTC()
+	start := end.Add(-lookback)
+	hashes := policyRuleHashes(policy)
+	query := MetricsQueryRequest{Tenant: "default"}
+	for _, hash := range hashes {
+		if hash == "" {
+			continue
+		}
+		query.Queries = append(query.Queries, MetricsQuery{
+			Kind: "RuleMetrics",
+			Selector: &MetricsSelector{
+				Requirements: []Requirement{{Key: "name", Operator: "equals", Values: []string{hash}}},
+			},
+			Fields:       ruleMetricsFields,
+			Function:     "none",
+			GroupByField: "reporterID",
+			SortOrder:    "ascending",
+			StartTime:    start.Format(time.RFC3339),
+			EndTime:      end.Format(time.RFC3339),
+		})
+	}
+
+	results := &MetricsQueryResponse{}
+	if len(query.Queries) > 0 {
+		jsonBytes, err := json.Marshal(query)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+
+		log.Printf("[DEBUG] Querying rule metrics for Security Policy %s: %s", policyName, jsonBytes)
+		req, err := http.NewRequestWithContext(ctx, "POST", config.Server+"/telemetry/v1/metrics", bytes.NewBuffer(jsonBytes))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.AddCookie(&http.Cookie{Name: "sid", Value: config.SID})
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			bodyBytes, _ := io.ReadAll(resp.Body)
+			return diag.Errorf("failed to query rule metrics for Security Policy %s: HTTP %d %s: %s", policyName, resp.StatusCode, resp.Status, bodyBytes)
+		}
+
+		if err := json.NewDecoder(resp.Body).Decode(results); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	rules, hitCounts, diags := flattenRuleStats(policy, hashes, results)
+
+	if err := d.Set("rules", rules); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("hit_counts", hitCounts); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(policyName)
+
+	return diags
+}
+
+// policyRuleHashes returns the hash PSM reports metrics under for each rule, or "" where it is not known. PSM lists
+// the rule hashes in the policy status in rule order, so they can only be matched to the rules when the status has
+// one entry per rule; otherwise the status belongs to another version of the policy, or to a policy that is not
+// propagated yet, and no hash is trusted.
+func policyRuleHashes(policy *NetworkSecurityPolicy) []string {
+	hashes := make([]string, len(policy.Spec.Rules))
+	if len(policy.Status.RuleStatus) != len(policy.Spec.Rules) {
+		return hashes
+	}
+	for i, status := range policy.Status.RuleStatus {
+		hashes[i] = status.RuleHash
+	}
+	return hashes
+}
+
+// flattenRuleStats builds the rules and hit_counts attributes from the metrics returned for the rules with a hash, in
+// the order they were queried. Rules without a hash are reported with metrics_available = false and left out of
+// hit_counts, so that they are not mistaken for rules that did not match any traffic. Rule names used more than
+// once are left out of hit_counts as well.
+func flattenRuleStats(policy *NetworkSecurityPolicy, hashes []string, results *MetricsQueryResponse) ([]interface{}, map[string]interface{}, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	rules := make([]interface{}, len(policy.Spec.Rules))
+	hitCounts := make(map[string]interface{})
+	nameCount := make(map[string]int)
+	var unknown, duplicates []string
+	result := 0
+	for i, rule := range policy.Spec.Rules {
+		r := map[string]interface{}{
+			"rule_name":         rule.Name,
+			"rule_index":        i,
+			"rule_hash":         hashes[i],
+			"action":            rule.Action,
+			"metrics_available": hashes[i] != "",
+			"last_hit":          "",
+		}
+		rules[i] = r
+
+		if rule.Name != "" {
+			nameCount[rule.Name]++
+			if nameCount[rule.Name] == 2 {
+				duplicates = append(duplicates, rule.Name)
+			}
+		}
+		if hashes[i] == "" {
+			unknown = append(unknown, ruleLabel(rule.Name, i))
+			continue
+		}
+
+		hits := &ruleHits{counters: make(map[string]int64)}
+		if result < len(results.Results) {
+			for _, series := range results.Results[result].Series {
+				hits.add(series)
+			}
+		}
+		result++
+
+		r["total_hits"] = int(hits.counters["TotalHits"])
+		r["tcp_hits"] = int(hits.counters["TcpHits"])
+		r["udp_hits"] = int(hits.counters["UdpHits"])
+		r["icmp_hits"] = int(hits.counters["IcmpHits"])
+		r["other_hits"] = int(hits.counters["OtherHits"])
+		if !hits.lastHit.IsZero() {
+			r["last_hit"] = hits.lastHit.Format(time.RFC3339)
+		}
+		if rule.Name != "" {
+			hitCounts[rule.Name] = int(hits.counters["TotalHits"])
+		}
+	}
+
+	for _, name := range duplicates {
+		delete(hitCounts, name)
+	}
+	if len(unknown) > 0 {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  fmt.Sprintf("No metrics for %d rule(s) of Security Policy %s", len(unknown), policy.Meta.Name),
+			Detail: fmt.Sprintf("PSM reports no rule hash for these rules, usually because the policy has not been "+
+				"propagated since it last changed:\n%s\n\nTheir hit counts are unknown and they have metrics_available = false.",
+				bulletList(unknown)),
+		})
+	}
+	if len(duplicates) > 0 {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  fmt.Sprintf("Duplicate rule names in Security Policy %s", policy.Meta.Name),
+			Detail: fmt.Sprintf("These names are used by more than one rule and are left out of hit_counts, use rules "+
+				"instead:\n%s", bulletList(duplicates)),
+		})
+	}
+
+	return rules, hitCounts, diags
+}
+
+// add folds one reporter's series into the totals. Counters are cumulative on each DSE, so the hits within the
+// lookback window are the increase from the first to the last sample. A counter lower than the sample before it
+// means the DSE restarted, in which case its count since the restart is added. The last hit is the latest sample
+// where TotalHits grew over the previous one.
+func (h *ruleHits) add(series MetricsSeries) {
+	column := func(name string) int {
+		for i, c := range series.Columns {
+			if c == name {
+				return i
+			}
+		}
+		return -1
+	}
+	timeColumn := column("time")
+
+	for n := 1; n < len(series.Values); n++ {
+		previous, values := series.Values[n-1], series.Values[n]
+		for _, field := range ruleMetricsFields {
+			h.counters[field] += counterIncrease(metricValue(previous, column(field)), metricValue(values, column(field)))
+		}
+
+		if counterIncrease(metricValue(previous, column("TotalHits")), metricValue(values, column("TotalHits"))) > 0 && timeColumn >= 0 {
+			if ts, ok := values[timeColumn].(string); ok {
+				if t, err := time.Parse(time.RFC3339, ts); err == nil && t.After(h.lastHit) {
+					h.lastHit = t
+				}
+			}
+		}
+	}
+}
+
+// counterIncrease returns how much a cumulative counter grew between two samples, treating a decrease as a reset
+// of the counter to zero.
+func counterIncrease(previous, current int64) int64 {
+	if current < previous {
+		return current
+	}
+	return current - previous
+}
+
+func metricValue(values []interface{}, i int) int64 {
+	if i < 0 || i >= len(values) {
+		return 0
+	}
+	switch v := values[i].(type) {
+	case float64:
+		return int64(v)
+	case string:
+		n, _ := strconv.ParseInt(v, 10, 64)
+		return n
+	}
+	return 0
+}
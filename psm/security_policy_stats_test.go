@@ -0,0 +1,248 @@
+package psm
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+func TestCounterIncrease(t *testing.T) {
+	tests := []struct {
+		previous, current, want int64
+	}{
+		{0, 0, 0},
+		{10, 25, 15},
+		{25, 25, 0},
+		{25, 5, 5},
+		{25, 0, 0},
+	}
+
+	for _, tt := range tests {
+		if got := counterIncrease(tt.previous, tt.current); got != tt.want {
+			t.Errorf("counterIncrease(%d, %d) = %d, want %d", tt.previous, tt.current, got, tt.want)
+		}
+	}
+}
+
+func TestMetricValue(t *testing.T) {
+	values := []interface{}{"2024-05-01T00:00:00Z", float64(42), "17", nil, "x"}
+
+	tests := []struct {
+		index int
+		want  int64
+	}{
+		{1, 42},
+		{2, 17},
+		{3, 0},
+		{4, 0},
+		{-1, 0},
+		{5, 0},
+	}
+
+	for _, tt := range tests {
+		if got := metricValue(values, tt.index); got != tt.want {
+			t.Errorf("metricValue(%d) = %d, want %d", tt.index, got, tt.want)
+		}
+	}
+}
+
+func TestRuleHitsAdd(t *testing.T) {
+	columns := []string{"time", "TotalHits", "TcpHits", "UdpHits", "IcmpHits", "OtherHits"}
+
+	tests := []struct {
+		name      string
+		series    [][][]interface{}
+		wantTotal int64
+		wantTCP   int64
+		wantLast  string
+	}{
+		{
+			name:   "single sample",
+			series: [][][]interface{}{{{"2024-05-01T00:00:00Z", 100.0, 100.0, 0.0, 0.0, 0.0}}},
+		},
+		{
+			name: "steady increase",
+			series: [][][]interface{}{{
+				{"2024-05-01T00:00:00Z", 100.0, 90.0, 10.0, 0.0, 0.0},
+				{"2024-05-01T01:00:00Z", 120.0, 105.0, 15.0, 0.0, 0.0},
+				{"2024-05-01T02:00:00Z", 130.0, 115.0, 15.0, 0.0, 0.0},
+				{"2024-05-01T03:00:00Z", 130.0, 115.0, 15.0, 0.0, 0.0},
+			}},
+			wantTotal: 30,
+			wantTCP:   25,
+			wantLast:  "2024-05-01T02:00:00Z",
+		},
+		{
+			name: "counter reset",
+			series: [][][]interface{}{{
+				{"2024-05-01T00:00:00Z", 100.0, 100.0, 0.0, 0.0, 0.0},
+				{"2024-05-01T01:00:00Z", 130.0, 130.0, 0.0, 0.0, 0.0},
+				{"2024-05-01T02:00:00Z", 5.0, 5.0, 0.0, 0.0, 0.0},
+			}},
+			wantTotal: 35,
+			wantTCP:   35,
+			wantLast:  "2024-05-01T02:00:00Z",
+		},
+		{
+			name: "two reporters",
+			series: [][][]interface{}{
+				{
+					{"2024-05-01T00:00:00Z", 10.0, 10.0, 0.0, 0.0, 0.0},
+					{"2024-05-01T01:00:00Z", 20.0, 20.0, 0.0, 0.0, 0.0},
+				},
+				{
+					{"2024-05-01T00:30:00Z", 500.0, 0.0, 0.0, 0.0, 500.0},
+					{"2024-05-01T01:30:00Z", 501.0, 0.0, 0.0, 0.0, 501.0},
+				},
+			},
+			wantTotal: 11,
+			wantTCP:   10,
+			wantLast:  "2024-05-01T01:30:00Z",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hits := &ruleHits{counters: make(map[string]int64)}
+			for _, values := range tt.series {
+				hits.add(MetricsSeries{Columns: columns, Values: values})
+			}
+
+			if got := hits.counters["TotalHits"]; got != tt.wantTotal {
+				t.Errorf("TotalHits = %d, want %d", got, tt.wantTotal)
+			}
+			if got := hits.counters["TcpHits"]; got != tt.wantTCP {
+				t.Errorf("TcpHits = %d, want %d", got, tt.wantTCP)
+			}
+			last := ""
+			if !hits.lastHit.IsZero() {
+				last = hits.lastHit.Format(time.RFC3339)
+			}
+			if last != tt.wantLast {
+				t.Errorf("lastHit = %q, want %q", last, tt.wantLast)
+			}
+		})
+	}
+}
+
+func testPolicy(ruleNames []string, hashes []string) *NetworkSecurityPolicy {
+	policy := &NetworkSecurityPolicy{}
+	policy.Meta.Name = "test"
+	for _, name := range ruleNames {
+		policy.Spec.Rules = append(policy.Spec.Rules, Rule{Name: name, Action: "permit"})
+	}
+	for _, hash := range hashes {
+		policy.Status.RuleStatus = append(policy.Status.RuleStatus, RuleStatus{RuleHash: hash})
+	}
+	return policy
+}
+
+func TestPolicyRuleHashes(t *testing.T) {
+	tests := []struct {
+		name   string
+		rules  []string
+		status []string
+		want   []string
+	}{
+		{"propagated", []string{"a", "b"}, []string{"h1", "h2"}, []string{"h1", "h2"}},
+		{"not propagated", []string{"a", "b"}, nil, []string{"", ""}},
+		{"status of an earlier version", []string{"a", "b", "c"}, []string{"h1", "h2"}, []string{"", "", ""}},
+		{"status with more rules", []string{"a"}, []string{"h1", "h2"}, []string{""}},
+		{"no rules", nil, nil, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := policyRuleHashes(testPolicy(tt.rules, tt.status))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("policyRuleHashes() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlattenRuleStats(t *testing.T) {
+	columns := []string{"time", "TotalHits"}
+	series := func(first, last float64) []MetricsSeries {
+		return []MetricsSeries{{Columns: columns, Values: [][]interface{}{
+			{"2024-05-01T00:00:00Z", first},
+			{"2024-05-01T01:00:00Z", last},
+		}}}
+	}
+
+	tests := []struct {
+		name          string
+		rules         []string
+		hashes        []string
+		results       [][]MetricsSeries
+		wantTotals    []interface{}
+		wantHitCounts map[string]interface{}
+		wantWarnings  []string
+	}{
+		{
+			name:          "all rules have metrics",
+			rules:         []string{"web", "ssh"},
+			hashes:        []string{"h1", "h2"},
+			results:       [][]MetricsSeries{series(10, 15), series(7, 7)},
+			wantTotals:    []interface{}{5, 0},
+			wantHitCounts: map[string]interface{}{"web": 5, "ssh": 0},
+		},
+		{
+			name:          "rules without hash are unknown",
+			rules:         []string{"web", "ssh", ""},
+			hashes:        []string{"", "h2", ""},
+			results:       [][]MetricsSeries{series(1, 4)},
+			wantTotals:    []interface{}{nil, 3, nil},
+			wantHitCounts: map[string]interface{}{"ssh": 3},
+			wantWarnings:  []string{"No metrics for 2 rule(s) of Security Policy test"},
+		},
+		{
+			name:          "duplicate names are left out of hit_counts",
+			rules:         []string{"web", "web", "ssh"},
+			hashes:        []string{"h1", "h2", "h3"},
+			results:       [][]MetricsSeries{series(0, 1), series(0, 2), series(0, 3)},
+			wantTotals:    []interface{}{1, 2, 3},
+			wantHitCounts: map[string]interface{}{"ssh": 3},
+			wantWarnings:  []string{"Duplicate rule names in Security Policy test"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := &MetricsQueryResponse{}
+			for _, s := range tt.results {
+				results.Results = append(results.Results, struct {
+					Series []MetricsSeries `json:"series"`
+				}{Series: s})
+			}
+
+			rules, hitCounts, diags := flattenRuleStats(testPolicy(tt.rules, nil), tt.hashes, results)
+
+			for i, want := range tt.wantTotals {
+				r := rules[i].(map[string]interface{})
+				if got := r["total_hits"]; got != want {
+					t.Errorf("rule %d total_hits = %v, want %v", i, got, want)
+				}
+				if got := r["metrics_available"]; got != (want != nil) {
+					t.Errorf("rule %d metrics_available = %v, want %v", i, got, want != nil)
+				}
+			}
+			if !reflect.DeepEqual(hitCounts, tt.wantHitCounts) {
+				t.Errorf("hit_counts = %v, want %v", hitCounts, tt.wantHitCounts)
+			}
+
+			var warnings []string
+			for _, d := range diags {
+				if d.Severity != diag.Warning {
+					t.Errorf("unexpected diagnostic %v", d)
+				}
+				warnings = append(warnings, d.Summary)
+			}
+			if !reflect.DeepEqual(warnings, tt.wantWarnings) {
+				t.Errorf("warnings = %q, want %q", warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
@@ -385,7 +385,7 @@ func resourceUserImport(ctx context.Context, d *schema.ResourceData, m interface
 	tenant := parts[0]
 	name := parts[2]
 
-	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/auth/v1/tenant/%s/users/%s", config.Server, tenant, name), nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/configs/auth/v1/tenant/%s/users/%s", config.Server, tenant, name), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -3,6 +3,10 @@ package psm
 import (
 	"bytes"
 	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -71,6 +75,14 @@ func ExpandStringSet(set *schema.Set) []string {
 	return result
 }
 
+func expandStringMap(m map[string]interface{}) map[string]string {
+	result := make(map[string]string, len(m))
+	for k, v := range m {
+		result[k] = v.(string)
+	}
+	return result
+}
+
 func FlattenStringList(list []string) *schema.Set {
 	set := schema.NewSet(schema.HashString, []interface{}{})
 	for _, v := range list {
@@ -553,3 +565,44 @@ func validateAddressFamily(val interface{}, key string) (warns []string, errs []
 	}
 	return
 }
+
+func validateDuration(val interface{}, key string) (warns []string, errs []error) {
+	v := val.(string)
+	if d, err := time.ParseDuration(v); err != nil || d <= 0 {
+		errs = append(errs, fmt.Errorf("%q must be a positive duration such as '30s' or '5m', got: %s", key, v))
+	}
+	return
+}
+
+var (
+	labelNameRegexp   = regexp.MustCompile(`^[A-Za-z0-9]([-A-Za-z0-9_.]*[A-Za-z0-9])?$`)
+	labelPrefixRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+)
+
+// validateLabelKey checks a label key against the syntax PSM accepts: a name of at most 63 alphanumerics, '-', '_'
+// or '.', starting and ending with an alphanumeric, optionally preceded by a DNS subdomain prefix and a '/'.
+func validateLabelKey(val interface{}, key string) (warns []string, errs []error) {
+	v := val.(string)
+	name := v
+	if prefix, rest, found := strings.Cut(v, "/"); found {
+		if len(prefix) > 253 || !labelPrefixRegexp.MatchString(prefix) {
+			errs = append(errs, fmt.Errorf("%q prefix must be a lowercase DNS subdomain of at most 253 characters, got: %s", key, v))
+		}
+		name = rest
+	}
+	if len(name) > 63 || !labelNameRegexp.MatchString(name) {
+		errs = append(errs, fmt.Errorf("%q must be at most 63 alphanumerics, '-', '_' or '.', starting and ending with an alphanumeric, got: %s", key, v))
+	}
+	return
+}
+
+// validateIntString returns a validator for strings holding a decimal integer between min and max.
+func validateIntString(min, max int64) schema.SchemaValidateFunc {
+	return func(val interface{}, key string) (warns []string, errs []error) {
+		v := val.(string)
+		if n, err := strconv.ParseInt(v, 10, 64); err != nil || n < min || n > max {
+			errs = append(errs, fmt.Errorf("%q must be an integer between %d and %d, got: %s", key, min, max, v))
+		}
+		return
+	}
+}
@@ -0,0 +1,71 @@
+package psm
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateDuration(t *testing.T) {
+	tests := []struct {
+		value   string
+		wantErr bool
+	}{
+		{"30s", false},
+		{"5m", false},
+		{"720h", false},
+		{"1h30m", false},
+		{"0s", true},
+		{"-5m", true},
+		{"5", true},
+		{"five minutes", true},
+		{"", true},
+	}
+
+	for _, tt := range tests {
+		_, errs := validateDuration(tt.value, "lookback")
+		if (len(errs) > 0) != tt.wantErr {
+			t.Errorf("validateDuration(%q) errors = %v, want error %v", tt.value, errs, tt.wantErr)
+		}
+	}
+}
+
+func TestExpandStringMap(t *testing.T) {
+	got := expandStringMap(map[string]interface{}{"tier": "web", "env": ""})
+
+	if want := map[string]string{"tier": "web", "env": ""}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expandStringMap() = %v, want %v", got, want)
+	}
+	if got := expandStringMap(nil); got == nil || len(got) != 0 {
+		t.Errorf("expandStringMap(nil) = %#v, want an empty map", got)
+	}
+}
+
+func TestValidateLabelKey(t *testing.T) {
+	tests := []struct {
+		value   string
+		wantErr bool
+	}{
+		{"tier", false},
+		{"app.kubernetes.io/name", false},
+		{"Tier_2", false},
+		{"a", false},
+		{"example.com/a-b.c", false},
+		{"", true},
+		{"-tier", true},
+		{"tier-", true},
+		{"tier name", true},
+		{"Example.com/tier", true},
+		{"/tier", true},
+		{"example.com/", true},
+		{strings.Repeat("a", 63), false},
+		{strings.Repeat("a", 64), true},
+	}
+
+	for _, tt := range tests {
+		_, errs := validateLabelKey(tt.value, "workload_label_key")
+		if (len(errs) > 0) != tt.wantErr {
+			t.Errorf("validateLabelKey(%q) errors = %v, want error %v", tt.value, errs, tt.wantErr)
+		}
+	}
+}
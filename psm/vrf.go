@@ -11,6 +11,7 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 func resourceVRF() *schema.Resource {
@@ -80,6 +81,37 @@ func resourceVRF() *schema.Resource {
 				Optional: true,
 				Default:  0,
 			},
+			"route_import_export": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Elem:        routeImportExportResource(),
+				Description: "Route distinguisher and route targets imported and exported by the VRF",
+			},
+			"static_route": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"prefix": {
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.IsCIDR,
+						},
+						"next_hop": {
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.IsIPAddress,
+						},
+						"admin_distance": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      1,
+							ValidateFunc: validation.IntBetween(1, 255),
+						},
+					},
+				},
+			},
 		},
 	}
 }
@@ -99,26 +131,34 @@ type VRF struct {
 		DisplayName     interface{} `json:"display-name"`
 	} `json:"meta"`
 	Spec struct {
-		Type                                                  string      `json:"type"`
-		RouterMacAddress                                      interface{} `json:"router-mac-address"`
-		VxlanVni                                              interface{} `json:"vxlan-vni"`
-		DefaultIpamPolicy                                     interface{} `json:"default-ipam-policy"`
-		IngressSecurityPolicy                                 []string    `json:"ingress-security-policy"`
-		EgressSecurityPolicy                                  []string    `json:"egress-security-policy"`
-		MaximumCpsPerNetworkPerDistributedServicesEntity      int         `json:"maximum-cps-per-network-per-distributed-services-entity"`
-		MaximumSessionsPerNetworkPerDistributedServicesEntity int         `json:"maximum-sessions-per-network-per-distributed-services-entity"`
-		FlowExportPolicy                                      []string    `json:"flow-export-policy"`
-		IngressNatPolicy                                      []string    `json:"ingress-nat-policy"`
-		EgressNatPolicy                                       []string    `json:"egress-nat-policy"`
-		IpsecPolicy                                           []string    `json:"ipsec-policy"`
-		SelectCPS                                             int         `json:"selectCPS"`
-		SelectSessions                                        int         `json:"selectSessions"`
-		ConnectionTracking                                    string      `json:"connection-tracking-mode"`
-		AllowSessionReuse                                     string      `json:"allow-session-reuse"`
-		IpFragmentsForwarding                                 string      `json:"ip-fragments-forwarding"`
+		Type                                                  string             `json:"type"`
+		RouterMacAddress                                      interface{}        `json:"router-mac-address"`
+		VxlanVni                                              interface{}        `json:"vxlan-vni"`
+		DefaultIpamPolicy                                     interface{}        `json:"default-ipam-policy"`
+		IngressSecurityPolicy                                 []string           `json:"ingress-security-policy"`
+		EgressSecurityPolicy                                  []string           `json:"egress-security-policy"`
+		MaximumCpsPerNetworkPerDistributedServicesEntity      int                `json:"maximum-cps-per-network-per-distributed-services-entity"`
+		MaximumSessionsPerNetworkPerDistributedServicesEntity int                `json:"maximum-sessions-per-network-per-distributed-services-entity"`
+		FlowExportPolicy                                      []string           `json:"flow-export-policy"`
+		IngressNatPolicy                                      []string           `json:"ingress-nat-policy"`
+		EgressNatPolicy                                       []string           `json:"egress-nat-policy"`
+		IpsecPolicy                                           []string           `json:"ipsec-policy"`
+		SelectCPS                                             int                `json:"selectCPS"`
+		SelectSessions                                        int                `json:"selectSessions"`
+		ConnectionTracking                                    string             `json:"connection-tracking-mode"`
+		AllowSessionReuse                                     string             `json:"allow-session-reuse"`
+		IpFragmentsForwarding                                 string             `json:"ip-fragments-forwarding"`
+		RouteImportExport                                     *RouteImportExport `json:"route-import-export,omitempty"`
+		StaticRoutes                                          []StaticRoute      `json:"static-routes,omitempty"`
 	} `json:"spec"`
 }
 
+type StaticRoute struct {
+	Prefix        string `json:"prefix"`
+	NextHop       string `json:"next-hop"`
+	AdminDistance int    `json:"admin-distance,omitempty"`
+}
+
 func resourceVRFCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	config := m.(*Config)
 	client := config.Client()
@@ -152,6 +192,8 @@ func resourceVRFCreate(ctx context.Context, d *schema.ResourceData, m interface{
 	if v, ok := d.GetOk("flow_export_policy"); ok {
 		vrf.Spec.FlowExportPolicy = expandStringList(v.([]interface{}))
 	}
+	vrf.Spec.RouteImportExport = expandRouteImportExport(d.Get("route_import_export").([]interface{}))
+	vrf.Spec.StaticRoutes = expandStaticRoutes(d.Get("static_route").([]interface{}))
 
 	if vrfName == "default" {
 		d.SetId("default")
@@ -248,6 +290,15 @@ func resourceVRFRead(ctx context.Context, d *schema.ResourceData, m interface{})
 	d.Set("flow_export_policy", vrf.Spec.FlowExportPolicy)
 	d.Set("maximum_cps_per_network", vrf.Spec.MaximumCpsPerNetworkPerDistributedServicesEntity)
 	d.Set("maximum_sessions_per_network", vrf.Spec.MaximumSessionsPerNetworkPerDistributedServicesEntity)
+	d.Set("static_route", flattenStaticRoutes(vrf.Spec.StaticRoutes))
+
+	// PSM fills in an automatic route distinguisher, only track the block when it carries configuration
+	rie := vrf.Spec.RouteImportExport
+	if _, configured := d.GetOk("route_import_export"); configured || (rie != nil && (len(rie.ExportRTs) > 0 || len(rie.ImportRTs) > 0 || !rie.RDAuto)) {
+		d.Set("route_import_export", flattenRouteImportExport(rie))
+	} else {
+		d.Set("route_import_export", nil)
+	}
 
 	return nil
 }
@@ -322,6 +373,8 @@ func resourceVRFUpdate(ctx context.Context, d *schema.ResourceData, m interface{
 	if v, ok := d.GetOk("flow_export_policy"); ok {
 		vrf.Spec.FlowExportPolicy = expandStringList(v.([]interface{}))
 	}
+	vrf.Spec.RouteImportExport = expandRouteImportExport(d.Get("route_import_export").([]interface{}))
+	vrf.Spec.StaticRoutes = expandStaticRoutes(d.Get("static_route").([]interface{}))
 
 	if vrfName == "default" {
 		d.SetId("default")
@@ -418,3 +471,32 @@ func resourceVRFImport(ctx context.Context, d *schema.ResourceData, m interface{
 
 	return []*schema.ResourceData{d}, nil
 }
+
+func expandStaticRoutes(list []interface{}) []StaticRoute {
+	routes := make([]StaticRoute, 0, len(list))
+	for _, v := range list {
+		route := v.(map[string]interface{})
+		routes = append(routes, StaticRoute{
+			Prefix:        route["prefix"].(string),
+			NextHop:       route["next_hop"].(string),
+			AdminDistance: route["admin_distance"].(int),
+		})
+	}
+	return routes
+}
+
+func flattenStaticRoutes(routes []StaticRoute) []interface{} {
+	result := make([]interface{}, 0, len(routes))
+	for _, route := range routes {
+		adminDistance := route.AdminDistance
+		if adminDistance == 0 {
+			adminDistance = 1
+		}
+		result = append(result, map[string]interface{}{
+			"prefix":         route.Prefix,
+			"next_hop":       route.NextHop,
+			"admin_distance": adminDistance,
+		})
+	}
+	return result
+}
@@ -0,0 +1,133 @@
+package psm
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceVRFRoutes() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceVRFRoutesRead,
+		Schema: map[string]*schema.Schema{
+			"virtual_router": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Name of the virtual router (VRF) to return the route table of",
+			},
+			"routes": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"prefix": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"next_hop": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"interface": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+			"prefixes": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Sorted, distinct prefixes present in the route table",
+			},
+		},
+	}
+}
+
+// RouteTable is the effective route table PSM maintains for each virtual router.
+type RouteTable struct {
+	Meta struct {
+		Name   string `json:"name"`
+		Tenant string `json:"tenant"`
+	} `json:"meta"`
+	Status struct {
+		Routes []Route `json:"routes"`
+	} `json:"status"`
+}
+
+type Route struct {
+	Prefix    string `json:"prefix"`
+	NextHop   string `json:"next-hop"`
+	Type      string `json:"type"`
+	Interface string `json:"interface"`
+}
+
+func dataSourceVRFRoutesRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	config := m.(*Config)
+	client := config.Client()
+
+	vrfName := d.Get("virtual_router").(string)
+	url := config.Server + "/configs/network/v1/tenant/default/route-tables/" + vrfName
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	req.AddCookie(&http.Cookie{Name: "sid", Value: config.SID})
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return diag.Errorf("failed to read route table of VRF %s: HTTP %d %s: %s", vrfName, resp.StatusCode, resp.Status, bodyBytes)
+	}
+
+	routeTable := &RouteTable{}
+	if err := json.NewDecoder(resp.Body).Decode(routeTable); err != nil {
+		return diag.FromErr(err)
+	}
+	log.Printf("[DEBUG] VRF %s has %d routes", vrfName, len(routeTable.Status.Routes))
+
+	routes := make([]interface{}, 0, len(routeTable.Status.Routes))
+	seen := make(map[string]bool)
+	prefixes := []string{}
+	for _, route := range routeTable.Status.Routes {
+		routes = append(routes, map[string]interface{}{
+			"prefix":    route.Prefix,
+			"next_hop":  route.NextHop,
+			"type":      route.Type,
+			"interface": route.Interface,
+		})
+		if !seen[route.Prefix] {
+			seen[route.Prefix] = true
+			prefixes = append(prefixes, route.Prefix)
+		}
+	}
+	sort.Strings(prefixes)
+
+	if err := d.Set("routes", routes); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("prefixes", prefixes); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(vrfName)
+
+	return nil
+}
@@ -0,0 +1,46 @@
+package psm
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestDataSourceVRFRoutesRead(t *testing.T) {
+	config := newTestServer(t, map[string]string{
+		"/configs/network/v1/tenant/default/route-tables/blue": `{
+			"meta": {"name": "blue"},
+			"status": {"routes": [
+				{"prefix": "10.1.0.0/16", "next-hop": "10.0.0.1", "type": "static"},
+				{"prefix": "0.0.0.0/0", "next-hop": "10.0.0.2", "type": "bgp"},
+				{"prefix": "10.1.0.0/16", "next-hop": "10.0.0.3", "type": "bgp"}
+			]}
+		}`,
+	})
+
+	d := schema.TestResourceDataRaw(t, dataSourceVRFRoutes().Schema, map[string]interface{}{"virtual_router": "blue"})
+	if diags := dataSourceVRFRoutesRead(context.Background(), d, config); diags.HasError() {
+		t.Fatalf("read error = %v", diags)
+	}
+
+	if got := d.Get("routes.#").(int); got != 3 {
+		t.Errorf("routes = %d, want 3", got)
+	}
+	if got := d.Get("routes.2.next_hop").(string); got != "10.0.0.3" {
+		t.Errorf("routes.2.next_hop = %q, want 10.0.0.3", got)
+	}
+	// Prefixes are listed once each, sorted
+	if got := expandStringList(d.Get("prefixes").([]interface{})); !reflect.DeepEqual(got, []string{"0.0.0.0/0", "10.1.0.0/16"}) {
+		t.Errorf("prefixes = %q", got)
+	}
+	if d.Id() != "blue" {
+		t.Errorf("id = %q, want blue", d.Id())
+	}
+
+	d = schema.TestResourceDataRaw(t, dataSourceVRFRoutes().Schema, map[string]interface{}{"virtual_router": "missing"})
+	if diags := dataSourceVRFRoutesRead(context.Background(), d, config); !diags.HasError() {
+		t.Errorf("read of an unknown VRF succeeded")
+	}
+}
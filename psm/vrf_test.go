@@ -0,0 +1,40 @@
+package psm
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStaticRoutesRoundTrip(t *testing.T) {
+	config := []interface{}{
+		map[string]interface{}{"prefix": "0.0.0.0/0", "next_hop": "10.0.0.1", "admin_distance": 1},
+		map[string]interface{}{"prefix": "192.168.0.0/16", "next_hop": "10.0.0.2", "admin_distance": 20},
+	}
+
+	routes := expandStaticRoutes(config)
+	want := []StaticRoute{
+		{Prefix: "0.0.0.0/0", NextHop: "10.0.0.1", AdminDistance: 1},
+		{Prefix: "192.168.0.0/16", NextHop: "10.0.0.2", AdminDistance: 20},
+	}
+	if !reflect.DeepEqual(routes, want) {
+		t.Fatalf("expandStaticRoutes() = %+v, want %+v", routes, want)
+	}
+
+	if got := flattenStaticRoutes(routes); !reflect.DeepEqual(got, config) {
+		t.Errorf("flattenStaticRoutes() = %v, want %v", got, config)
+	}
+}
+
+func TestFlattenStaticRoutesDefaultAdminDistance(t *testing.T) {
+	// PSM leaves out an admin distance of 1, which is also the default of the schema
+	got := flattenStaticRoutes([]StaticRoute{{Prefix: "10.1.0.0/16", NextHop: "10.0.0.1"}})
+
+	want := []interface{}{map[string]interface{}{"prefix": "10.1.0.0/16", "next_hop": "10.0.0.1", "admin_distance": 1}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("flattenStaticRoutes() = %v, want %v", got, want)
+	}
+
+	if got := expandStaticRoutes(nil); got == nil || len(got) != 0 {
+		t.Errorf("expandStaticRoutes(nil) = %#v, want an empty list", got)
+	}
+}
@@ -7,10 +7,15 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"net"
 	"net/http"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 func resourceWorkload() *schema.Resource {
@@ -22,6 +27,7 @@ func resourceWorkload() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: resourceWorkloadImport,
 		},
+		CustomizeDiff: resourceWorkloadCustomizeDiff,
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:     schema.TypeString,
@@ -33,43 +39,89 @@ func resourceWorkload() *schema.Resource {
 				Required: true,
 			},
 			"migration_timeout": {
-				Type:     schema.TypeString,
-				Optional: true,
-				Default:  "60s",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "60s",
+				ValidateFunc: validateDuration,
 			},
-			"interface": {
-				Type:     schema.TypeList,
-				Required: true,
+			"migration_status": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Status of the last migration of the workload between hosts",
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
-						"mac_address": {
+						"stage": {
 							Type:     schema.TypeString,
-							Required: true,
-						},
-						"external_vlan": {
-							Type:     schema.TypeInt,
-							Required: true,
+							Computed: true,
 						},
-						"ip_addresses": {
-							Type:     schema.TypeList,
-							Required: true,
-							Elem:     &schema.Schema{Type: schema.TypeString},
-						},
-						"micro_seg_vlan": {
-							Type:     schema.TypeInt,
-							Optional: true,
+						"status": {
+							Type:     schema.TypeString,
+							Computed: true,
 						},
-						"network": {
+						"started_at": {
 							Type:     schema.TypeString,
-							Optional: true,
+							Computed: true,
 						},
-						"vni": {
-							Type:     schema.TypeInt,
-							Optional: true,
+						"completed_at": {
+							Type:     schema.TypeString,
+							Computed: true,
 						},
 					},
 				},
 			},
+			"labels": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Labels of the workload, matched by workload group label selectors",
+			},
+			"interface": {
+				Type:     schema.TypeList,
+				Required: true,
+				Elem:     workloadInterfaceResource(),
+			},
+		},
+	}
+}
+
+// workloadInterfaceResource is the schema of a workload interface, shared by psm_workload and psm_workload_set.
+func workloadInterfaceResource() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"mac_address": {
+				Type:             schema.TypeString,
+				Required:         true,
+				ValidateFunc:     validateMACAddress,
+				DiffSuppressFunc: suppressEquivalentMACAddress,
+				Description:      "MAC address in any common notation, sent to PSM as aaaa.bbbb.cccc",
+			},
+			"external_vlan": {
+				Type:         schema.TypeInt,
+				Required:     true,
+				ValidateFunc: validation.IntBetween(1, 4095),
+			},
+			"ip_addresses": {
+				Type:     schema.TypeList,
+				Required: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.IsIPAddress,
+				},
+			},
+			"micro_seg_vlan": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntBetween(1, 4095),
+			},
+			"network": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"vni": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntBetween(1, maxVNI),
+			},
 		},
 	}
 }
@@ -78,28 +130,41 @@ type Workload struct {
 	Kind       string `json:"kind"`
 	APIVersion string `json:"api-version"`
 	Meta       struct {
-		Name            string `json:"name"`
-		Tenant          string `json:"tenant"`
-		Namespace       string `json:"namespace"`
-		GenerationID    string `json:"generation-id"`
-		ResourceVersion string `json:"resource-version"`
-		UUID            string `json:"uuid"`
-		CreationTime    string `json:"creation-time"`
-		ModTime         string `json:"mod-time"`
-		SelfLink        string `json:"self-link"`
+		Name            string            `json:"name"`
+		Tenant          string            `json:"tenant"`
+		Namespace       string            `json:"namespace"`
+		GenerationID    string            `json:"generation-id"`
+		ResourceVersion string            `json:"resource-version"`
+		UUID            string            `json:"uuid"`
+		CreationTime    string            `json:"creation-time"`
+		ModTime         string            `json:"mod-time"`
+		SelfLink        string            `json:"self-link"`
+		Labels          map[string]string `json:"labels,omitempty"`
 	} `json:"meta"`
 	Spec struct {
-		HostName         string `json:"host-name"`
-		MigrationTimeout string `json:"migration-timeout"`
-		Interfaces       []struct {
-			MacAddress   string   `json:"mac-address"`
-			MicroSegVlan *int     `json:"micro-seg-vlan,omitempty"`
-			ExternalVlan int      `json:"external-vlan"`
-			IPAddresses  []string `json:"ip-addresses"`
-			Network      *string  `json:"network,omitempty"`
-			Vni          *int     `json:"vni,omitempty"`
-		} `json:"interfaces"`
+		HostName         string              `json:"host-name"`
+		MigrationTimeout string              `json:"migration-timeout"`
+		Interfaces       []WorkloadInterface `json:"interfaces"`
 	} `json:"spec"`
+	Status struct {
+		MigrationStatus *WorkloadMigrationStatus `json:"migration-status,omitempty"`
+	} `json:"status"`
+}
+
+type WorkloadInterface struct {
+	MacAddress   string   `json:"mac-address"`
+	MicroSegVlan *int     `json:"micro-seg-vlan,omitempty"`
+	ExternalVlan int      `json:"external-vlan"`
+	IPAddresses  []string `json:"ip-addresses"`
+	Network      *string  `json:"network,omitempty"`
+	Vni          *int     `json:"vni,omitempty"`
+}
+
+type WorkloadMigrationStatus struct {
+	Stage       string `json:"stage"`
+	Status      string `json:"status"`
+	StartedAt   string `json:"started-at"`
+	CompletedAt string `json:"completed-at"`
 }
 
 func resourceWorkloadCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
@@ -113,38 +178,11 @@ func resourceWorkloadCreate(ctx context.Context, d *schema.ResourceData, m inter
 	workload.Meta.Name = d.Get("name").(string)
 	workload.Meta.Namespace = "default"
 	workload.Meta.Tenant = "default"
+	workload.Meta.Labels = expandStringMap(d.Get("labels").(map[string]interface{}))
 	workload.Spec.HostName = d.Get("host_name").(string)
 	workload.Spec.MigrationTimeout = d.Get("migration_timeout").(string)
 
-	interfaces := d.Get("interface").([]interface{})
-	for _, iface := range interfaces {
-		ifaceMap := iface.(map[string]interface{})
-		workloadIface := struct {
-			MacAddress   string   `json:"mac-address"`
-			MicroSegVlan *int     `json:"micro-seg-vlan,omitempty"`
-			ExternalVlan int      `json:"external-vlan"`
-			IPAddresses  []string `json:"ip-addresses"`
-			Network      *string  `json:"network,omitempty"`
-			Vni          *int     `json:"vni,omitempty"`
-		}{
-			MacAddress:   ifaceMap["mac_address"].(string),
-			ExternalVlan: ifaceMap["external_vlan"].(int),
-			IPAddresses:  expandStringList(ifaceMap["ip_addresses"].([]interface{})),
-		}
-		if v, ok := ifaceMap["micro_seg_vlan"]; ok {
-			microSegVlan := v.(int)
-			workloadIface.MicroSegVlan = &microSegVlan
-		}
-		if v, ok := ifaceMap["network"]; ok {
-			network := v.(string)
-			workloadIface.Network = &network
-		}
-		if v, ok := ifaceMap["vni"]; ok {
-			vni := v.(int)
-			workloadIface.Vni = &vni
-		}
-		workload.Spec.Interfaces = append(workload.Spec.Interfaces, workloadIface)
-	}
+	workload.Spec.Interfaces = expandWorkloadInterfaces(d.Get("interface").([]interface{}))
 
 	jsonBytes, err := json.Marshal(workload)
 	if err != nil {
@@ -219,26 +257,10 @@ func resourceWorkloadRead(ctx context.Context, d *schema.ResourceData, m interfa
 	d.Set("name", workload.Meta.Name)
 	d.Set("host_name", workload.Spec.HostName)
 	d.Set("migration_timeout", workload.Spec.MigrationTimeout)
+	d.Set("labels", workload.Meta.Labels)
+	d.Set("migration_status", flattenWorkloadMigrationStatus(workload.Status.MigrationStatus))
 
-	interfaces := make([]interface{}, len(workload.Spec.Interfaces))
-	for i, iface := range workload.Spec.Interfaces {
-		ifaceMap := map[string]interface{}{
-			"mac_address":   iface.MacAddress,
-			"external_vlan": iface.ExternalVlan,
-			"ip_addresses":  iface.IPAddresses,
-		}
-		if iface.MicroSegVlan != nil {
-			ifaceMap["micro_seg_vlan"] = *iface.MicroSegVlan
-		}
-		if iface.Network != nil {
-			ifaceMap["network"] = *iface.Network
-		}
-		if iface.Vni != nil {
-			ifaceMap["vni"] = *iface.Vni
-		}
-		interfaces[i] = ifaceMap
-	}
-	d.Set("interface", interfaces)
+	d.Set("interface", flattenWorkloadInterfaces(workload.Spec.Interfaces))
 
 	return nil
 }
@@ -271,29 +293,243 @@ func resourceWorkloadUpdate(ctx context.Context, d *schema.ResourceData, m inter
 	}
 
 	workload := currentWorkload
+	if d.HasChange("labels") {
+		workload.Meta.Labels = expandStringMap(d.Get("labels").(map[string]interface{}))
+	}
+	// A new host is reached through the migration workflow once the rest of the workload is updated
 	workload.Spec.HostName = d.Get("host_name").(string)
+	if d.HasChange("host_name") {
+		workload.Spec.HostName = currentWorkload.Spec.HostName
+	}
 	workload.Spec.MigrationTimeout = d.Get("migration_timeout").(string)
 
-	interfaces := d.Get("interface").([]interface{})
-	workload.Spec.Interfaces = []struct {
-		MacAddress   string   `json:"mac-address"`
-		MicroSegVlan *int     `json:"micro-seg-vlan,omitempty"`
-		ExternalVlan int      `json:"external-vlan"`
-		IPAddresses  []string `json:"ip-addresses"`
-		Network      *string  `json:"network,omitempty"`
-		Vni          *int     `json:"vni,omitempty"`
-	}{}
+	workload.Spec.Interfaces = expandWorkloadInterfaces(d.Get("interface").([]interface{}))
+
+	if d.HasChangeExcept("host_name") {
+		if diags := putWorkload(ctx, config, url, &workload); diags.HasError() {
+			return diags
+		}
+	}
+
+	if d.HasChange("host_name") {
+		workload.Meta.ResourceVersion = ""
+		workload.Spec.HostName = d.Get("host_name").(string)
+		if diags := migrateWorkload(ctx, d, config, &workload); diags.HasError() {
+			return append(diags, resourceWorkloadRead(ctx, d, m)...)
+		}
+	}
+
+	return resourceWorkloadRead(ctx, d, m)
+}
+
+func putWorkload(ctx context.Context, config *Config, url string, workload *Workload) diag.Diagnostics {
+	client := config.Client()
+
+	jsonBytes, err := json.Marshal(workload)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	log.Printf("[DEBUG] Workload update payload: %s", string(jsonBytes))
+
+	req, err := http.NewRequestWithContext(ctx, "PUT", url, bytes.NewBuffer(jsonBytes))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	req.AddCookie(&http.Cookie{Name: "sid", Value: config.SID})
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		errMsg := fmt.Sprintf("failed to update workload: HTTP %d %s: %s", resp.StatusCode, resp.Status, bodyBytes)
+		return diag.Errorf(errMsg)
+	}
+
+	return nil
+}
+
+// migrateWorkload moves a workload to the host in its spec using the PSM migration workflow: the migration is
+// started, and once PSM reports the new host ready it is finished. If either step fails or migration_timeout
+// expires, the migration is aborted so the workload stays on its current host.
+func migrateWorkload(ctx context.Context, d *schema.ResourceData, config *Config, workload *Workload) diag.Diagnostics {
+	name := workload.Meta.Name
+	migrationTimeout, _ := time.ParseDuration(d.Get("migration_timeout").(string))
+	timeout := time.After(migrationTimeout)
+
+	abort := func(reason string) diag.Diagnostics {
+		diags := diag.Errorf("migration of workload %s to host %s failed: %s", name, workload.Spec.HostName, reason)
+		if err := workloadMigrationAction(ctx, config, workload, "AbortMigration"); err != nil {
+			diags = append(diags, diag.Errorf("failed to abort migration of workload %s: %v", name, err)...)
+		}
+		return diags
+	}
+
+	// The status of an earlier migration stays on the workload until PSM reports on the new one, remember it so it
+	// is not mistaken for the outcome of this migration
+	current, err := getWorkload(ctx, config, name)
+	if err != nil {
+		return diag.Errorf("failed to read workload %s before migration: %v", name, err)
+	}
+	since := migrationSince{startedAt: time.Now()}
+	if current.Status.MigrationStatus != nil {
+		since.previous = current.Status.MigrationStatus.StartedAt
+	}
+
+	log.Printf("[DEBUG] Starting migration of workload %s to host %s", name, workload.Spec.HostName)
+	if err := workloadMigrationAction(ctx, config, workload, "StartMigration"); err != nil {
+		return diag.Errorf("failed to start migration of workload %s: %v", name, err)
+	}
+	stage, reason := waitForWorkloadMigration(ctx, config, name, since, timeout, "migration-start", "migration-done")
+	if reason != "" {
+		return abort(reason)
+	}
+	if stage == "migration-done" {
+		return nil
+	}
+
+	log.Printf("[DEBUG] Finishing migration of workload %s to host %s", name, workload.Spec.HostName)
+	if err := workloadMigrationAction(ctx, config, workload, "FinishMigration"); err != nil {
+		return abort(err.Error())
+	}
+	if _, reason := waitForWorkloadMigration(ctx, config, name, since, timeout, "migration-done"); reason != "" {
+		return abort(reason)
+	}
+
+	return nil
+}
+
+// migrationClockSkew is how far the PSM clock may be behind ours when comparing the start of a migration.
+const migrationClockSkew = time.Minute
+
+// workloadMigrationPollInterval is how often the workload is read while waiting for a migration stage.
+var workloadMigrationPollInterval = 5 * time.Second
+
+// migrationSince identifies the migration being waited for: the time it was started at, and the started-at of the
+// migration status the workload had before.
+type migrationSince struct {
+	startedAt time.Time
+	previous  string
+}
+
+// isCurrent reports whether a migration status belongs to the migration started at since, rather than being left
+// over from an earlier one.
+func (since migrationSince) isCurrent(status *WorkloadMigrationStatus) bool {
+	if status == nil || status.StartedAt == "" {
+		return false
+	}
+	if status.StartedAt == since.previous {
+		return false
+	}
+	if startedAt, err := time.Parse(time.RFC3339, status.StartedAt); err == nil && startedAt.Before(since.startedAt.Add(-migrationClockSkew)) {
+		return false
+	}
+	return true
+}
+
+// waitForWorkloadMigration polls the workload until the migration started at since reaches one of stages with status
+// done, and returns that stage. Otherwise it returns why the migration did not get there. Status left over from an
+// earlier migration is ignored.
+func waitForWorkloadMigration(ctx context.Context, config *Config, name string, since migrationSince, timeout <-chan time.Time, stages ...string) (string, string) {
+	ticker := time.NewTicker(workloadMigrationPollInterval)
+	defer ticker.Stop()
+
+	status := &WorkloadMigrationStatus{}
+	for {
+		select {
+		case <-timeout:
+			return "", fmt.Sprintf("timeout waiting for stage %s, last stage %q status %q", strings.Join(stages, " or "), status.Stage, status.Status)
+		case <-ticker.C:
+			workload, err := getWorkload(ctx, config, name)
+			if err != nil {
+				return "", err.Error()
+			}
+			if !since.isCurrent(workload.Status.MigrationStatus) {
+				log.Printf("[DEBUG] Migration of workload %s: waiting for PSM to report on the new migration", name)
+				continue
+			}
+			status = workload.Status.MigrationStatus
+			log.Printf("[DEBUG] Migration of workload %s: stage %q status %q", name, status.Stage, status.Status)
+
+			switch status.Status {
+			case "failed", "timed-out":
+				return "", fmt.Sprintf("PSM reported stage %q status %q", status.Stage, status.Status)
+			case "done":
+				if slices.Contains(stages, status.Stage) {
+					return status.Stage, ""
+				}
+			}
+		case <-ctx.Done():
+			return "", ctx.Err().Error()
+		}
+	}
+}
+
+// workloadMigrationAction posts the workload to one of the StartMigration, FinishMigration or AbortMigration actions.
+func workloadMigrationAction(ctx context.Context, config *Config, workload *Workload, action string) error {
+	jsonBytes, err := json.Marshal(workload)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/configs/workload/v1/tenant/default/workloads/%s/%s", config.Server, workload.Meta.Name, action)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonBytes))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.AddCookie(&http.Cookie{Name: "sid", Value: config.SID})
+
+	resp, err := config.Client().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%s: HTTP %d %s: %s", action, resp.StatusCode, resp.Status, bodyBytes)
+	}
+	return nil
+}
+
+func getWorkload(ctx context.Context, config *Config, name string) (*Workload, error) {
+	url := fmt.Sprintf("%s/configs/workload/v1/tenant/default/workloads/%s", config.Server, name)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.AddCookie(&http.Cookie{Name: "sid", Value: config.SID})
+
+	resp, err := config.Client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to read workload %s: HTTP %d %s: %s", name, resp.StatusCode, resp.Status, bodyBytes)
+	}
+
+	workload := &Workload{}
+	if err := json.NewDecoder(resp.Body).Decode(workload); err != nil {
+		return nil, err
+	}
+	return workload, nil
+}
+
+func expandWorkloadInterfaces(interfaces []interface{}) []WorkloadInterface {
+	result := make([]WorkloadInterface, 0, len(interfaces))
 	for _, iface := range interfaces {
 		ifaceMap := iface.(map[string]interface{})
-		workloadIface := struct {
-			MacAddress   string   `json:"mac-address"`
-			MicroSegVlan *int     `json:"micro-seg-vlan,omitempty"`
-			ExternalVlan int      `json:"external-vlan"`
-			IPAddresses  []string `json:"ip-addresses"`
-			Network      *string  `json:"network,omitempty"`
-			Vni          *int     `json:"vni,omitempty"`
-		}{
-			MacAddress:   ifaceMap["mac_address"].(string),
+		workloadIface := WorkloadInterface{
+			MacAddress:   normalizeMACAddress(ifaceMap["mac_address"].(string)),
 			ExternalVlan: ifaceMap["external_vlan"].(int),
 			IPAddresses:  expandStringList(ifaceMap["ip_addresses"].([]interface{})),
 		}
@@ -309,36 +545,71 @@ func resourceWorkloadUpdate(ctx context.Context, d *schema.ResourceData, m inter
 			vni := v.(int)
 			workloadIface.Vni = &vni
 		}
-		workload.Spec.Interfaces = append(workload.Spec.Interfaces, workloadIface)
+		result = append(result, workloadIface)
 	}
+	return result
+}
 
-	jsonBytes, err := json.Marshal(workload)
-	if err != nil {
-		return diag.FromErr(err)
+func flattenWorkloadInterfaces(interfaces []WorkloadInterface) []interface{} {
+	result := make([]interface{}, len(interfaces))
+	for i, iface := range interfaces {
+		ifaceMap := map[string]interface{}{
+			"mac_address":   iface.MacAddress,
+			"external_vlan": iface.ExternalVlan,
+			"ip_addresses":  iface.IPAddresses,
+		}
+		if iface.MicroSegVlan != nil {
+			ifaceMap["micro_seg_vlan"] = *iface.MicroSegVlan
+		}
+		if iface.Network != nil {
+			ifaceMap["network"] = *iface.Network
+		}
+		if iface.Vni != nil {
+			ifaceMap["vni"] = *iface.Vni
+		}
+		result[i] = ifaceMap
 	}
+	return result
+}
 
-	log.Printf("[DEBUG] Workload update payload: %s", string(jsonBytes))
-
-	req, err = http.NewRequestWithContext(ctx, "PUT", url, bytes.NewBuffer(jsonBytes))
+// listWorkloads returns every workload of the default tenant.
+func listWorkloads(ctx context.Context, config *Config) ([]Workload, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", config.Server+"/configs/workload/v1/tenant/default/workloads", nil)
 	if err != nil {
-		return diag.FromErr(err)
+		return nil, err
 	}
-
 	req.AddCookie(&http.Cookie{Name: "sid", Value: config.SID})
 
-	resp, err = client.Do(req)
+	resp, err := config.Client().Do(req)
 	if err != nil {
-		return diag.FromErr(err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		bodyBytes, _ := io.ReadAll(resp.Body)
-		errMsg := fmt.Sprintf("failed to update workload: HTTP %d %s: %s", resp.StatusCode, resp.Status, bodyBytes)
-		return diag.Errorf(errMsg)
+		return nil, fmt.Errorf("failed to list workloads: HTTP %d %s: %s", resp.StatusCode, resp.Status, bodyBytes)
 	}
 
-	return resourceWorkloadRead(ctx, d, m)
+	var list struct {
+		Items []Workload `json:"items"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+func flattenWorkloadMigrationStatus(status *WorkloadMigrationStatus) []interface{} {
+	if status == nil {
+		return nil
+	}
+	return []interface{}{map[string]interface{}{
+		"stage":        status.Stage,
+		"status":       status.Status,
+		"started_at":   status.StartedAt,
+		"completed_at": status.CompletedAt,
+	}}
 }
 
 func resourceWorkloadDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
@@ -403,26 +674,76 @@ func resourceWorkloadImport(ctx context.Context, d *schema.ResourceData, m inter
 	d.Set("name", workload.Meta.Name)
 	d.Set("host_name", workload.Spec.HostName)
 	d.Set("migration_timeout", workload.Spec.MigrationTimeout)
+	d.Set("labels", workload.Meta.Labels)
+	d.Set("migration_status", flattenWorkloadMigrationStatus(workload.Status.MigrationStatus))
 
-	interfaces := make([]interface{}, len(workload.Spec.Interfaces))
-	for i, iface := range workload.Spec.Interfaces {
-		ifaceMap := map[string]interface{}{
-			"mac_address":   iface.MacAddress,
-			"external_vlan": iface.ExternalVlan,
-			"ip_addresses":  iface.IPAddresses,
-		}
-		if iface.MicroSegVlan != nil {
-			ifaceMap["micro_seg_vlan"] = *iface.MicroSegVlan
+	d.Set("interface", flattenWorkloadInterfaces(workload.Spec.Interfaces))
+
+	return []*schema.ResourceData{d}, nil
+}
+
+// maxVNI is the largest VXLAN network identifier, VNIs being 24 bits wide.
+const maxVNI = 1<<24 - 1
+
+// parseMACAddress parses a MAC address written as aa:bb:cc:dd:ee:ff, aa-bb-cc-dd-ee-ff, aabb.ccdd.eeff or
+// aabbccddeeff, in either case.
+func parseMACAddress(mac string) (net.HardwareAddr, bool) {
+	if len(mac) == 12 {
+		mac = mac[0:4] + "." + mac[4:8] + "." + mac[8:12]
+	}
+	hw, err := net.ParseMAC(mac)
+	if err != nil || len(hw) != 6 {
+		return nil, false
+	}
+	return hw, true
+}
+
+// normalizeMACAddress returns a MAC address in the dotted aaaa.bbbb.cccc form PSM uses. Addresses that cannot be
+// parsed are returned unchanged.
+func normalizeMACAddress(mac string) string {
+	hw, ok := parseMACAddress(mac)
+	if !ok {
+		return mac
+	}
+	return fmt.Sprintf("%02x%02x.%02x%02x.%02x%02x", hw[0], hw[1], hw[2], hw[3], hw[4], hw[5])
+}
+
+func validateMACAddress(val interface{}, key string) (warns []string, errs []error) {
+	v := val.(string)
+	if _, ok := parseMACAddress(v); !ok {
+		errs = append(errs, fmt.Errorf("%q must be a MAC address such as 'aabb.ccdd.eeff' or 'aa:bb:cc:dd:ee:ff', got: %s", key, v))
+	}
+	return
+}
+
+func suppressEquivalentMACAddress(k, old, new string, d *schema.ResourceData) bool {
+	return normalizeMACAddress(old) == normalizeMACAddress(new)
+}
+
+// resourceWorkloadCustomizeDiff rejects interfaces sharing a MAC address, whatever notation they are written in.
+func resourceWorkloadCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	return checkUniqueMACAddresses(d.Get("interface").([]interface{}), func(i int) bool {
+		return d.NewValueKnown(fmt.Sprintf("interface.%d.mac_address", i))
+	})
+}
+
+// checkUniqueMACAddresses returns an error if two interfaces share a MAC address. Interfaces for which known returns
+// false are skipped.
+func checkUniqueMACAddresses(interfaces []interface{}, known func(i int) bool) error {
+	seen := make(map[string]int)
+	for i, iface := range interfaces {
+		ifaceMap, ok := iface.(map[string]interface{})
+		if !ok || !known(i) {
+			continue
 		}
-		if iface.Network != nil {
-			ifaceMap["network"] = *iface.Network
+		mac := normalizeMACAddress(ifaceMap["mac_address"].(string))
+		if mac == "" {
+			continue
 		}
-		if iface.Vni != nil {
-			ifaceMap["vni"] = *iface.Vni
+		if j, ok := seen[mac]; ok {
+			return fmt.Errorf("interface.%d.mac_address: MAC address %s is already used by interface.%d", i, mac, j)
 		}
-		interfaces[i] = ifaceMap
+		seen[mac] = i
 	}
-	d.Set("interface", interfaces)
-
-	return []*schema.ResourceData{d}, nil
+	return nil
 }
@@ -8,6 +8,8 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"slices"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -19,6 +21,10 @@ func resourceWorkloadGroup() *schema.Resource {
 		ReadContext:   resourceWorkloadGroupRead,
 		UpdateContext: resourceWorkloadGroupUpdate,
 		DeleteContext: resourceWorkloadGroupDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceWorkloadGroupImport,
+		},
+		CustomizeDiff: resourceWorkloadGroupCustomizeDiff,
 
 		Schema: map[string]*schema.Schema{
 			"name": {
@@ -38,12 +44,15 @@ func resourceWorkloadGroup() *schema.Resource {
 							Elem: &schema.Resource{
 								Schema: map[string]*schema.Schema{
 									"workload_label_key": {
-										Type:     schema.TypeString,
-										Required: true,
+										Type:         schema.TypeString,
+										Required:     true,
+										ValidateFunc: validateLabelKey,
 									},
 									"operator": {
-										Type:     schema.TypeString,
-										Required: true,
+										Type:             schema.TypeString,
+										Required:         true,
+										ValidateFunc:     validateWorkloadSelectorOperator,
+										DiffSuppressFunc: suppressEquivalentWorkloadSelectorOperator,
 									},
 									"values": {
 										Type:     schema.TypeList,
@@ -62,6 +71,7 @@ func resourceWorkloadGroup() *schema.Resource {
 				Optional: true,
 				ForceNew: false,
 			},
+			"force_detach": forceDetachSchema(),
 		},
 	}
 }
@@ -97,6 +107,41 @@ type WorkloadSelector struct {
 	Requirements []Requirement `json:"requirements"`
 }
 
+// workloadSelectorOperators are the operators PSM accepts in a label requirement.
+var workloadSelectorOperators = []string{"equals", "notEquals", "in", "notIn"}
+
+// normalizeWorkloadSelectorOperator returns the PSM spelling of an operator. Earlier releases documented the operators
+// as In, NotIn, Equals and NotEquals, so any case is accepted.
+func normalizeWorkloadSelectorOperator(operator string) string {
+	for _, op := range workloadSelectorOperators {
+		if strings.EqualFold(op, operator) {
+			return op
+		}
+	}
+	return operator
+}
+
+// validateWorkloadSelectorOperator accepts the PSM operators in any case, with a deprecation warning for spellings
+// other than PSM's own. Exists and DoesNotExist were documented by earlier releases but are not supported by PSM.
+func validateWorkloadSelectorOperator(val interface{}, key string) (warns []string, errs []error) {
+	operator := val.(string)
+	normalized := normalizeWorkloadSelectorOperator(operator)
+	switch {
+	case slices.Contains(workloadSelectorOperators, operator):
+	case slices.Contains(workloadSelectorOperators, normalized):
+		warns = append(warns, fmt.Sprintf("%s: operator %q is deprecated, use %q", key, operator, normalized))
+	case strings.EqualFold(operator, "exists") || strings.EqualFold(operator, "doesNotExist"):
+		errs = append(errs, fmt.Errorf("%s: operator %q is not supported by PSM, use in or notIn with the label values instead", key, operator))
+	default:
+		errs = append(errs, fmt.Errorf("%s: expected operator to be one of %v, got %q", key, workloadSelectorOperators, operator))
+	}
+	return warns, errs
+}
+
+func suppressEquivalentWorkloadSelectorOperator(k, old, new string, d *schema.ResourceData) bool {
+	return normalizeWorkloadSelectorOperator(old) == normalizeWorkloadSelectorOperator(new)
+}
+
 type PolicyList struct {
 	Items []PolicyListItem `json:"items"`
 }
@@ -133,7 +178,7 @@ func resourceWorkloadGroupCreate(ctx context.Context, d *schema.ResourceData, m
 			reqMap := req.(map[string]interface{})
 			requirement := Requirement{
 				Key:      reqMap["workload_label_key"].(string),
-				Operator: reqMap["operator"].(string),
+				Operator: normalizeWorkloadSelectorOperator(reqMap["operator"].(string)),
 				Values:   convertInterfaceToStringSlice(reqMap["values"]),
 			}
 			requirements = append(requirements, requirement)
@@ -222,14 +267,71 @@ func resourceWorkloadGroupRead(ctx context.Context, d *schema.ResourceData, m in
 	}
 
 	d.Set("name", workloadgroup.Meta.Name)
-	if len(workloadgroup.Spec.WorkloadSelector) > 0 && len(workloadgroup.Spec.WorkloadSelector[0].Requirements) > 0 {
-		d.Set("workload_label_selector", workloadgroup.Spec.WorkloadSelector[0].Requirements)
-	}
+	d.Set("workload_selector", flattenWorkloadSelectors(workloadgroup.Spec.WorkloadSelector))
 	d.Set("ip_collections", workloadgroup.Spec.IpCollections)
 
 	return nil
 }
 
+func flattenWorkloadSelectors(selectors []WorkloadSelector) []interface{} {
+	result := make([]interface{}, 0, len(selectors))
+	for _, selector := range selectors {
+		requirements := make([]interface{}, 0, len(selector.Requirements))
+		for _, requirement := range selector.Requirements {
+			requirements = append(requirements, map[string]interface{}{
+				"workload_label_key": requirement.Key,
+				"operator":           requirement.Operator,
+				"values":             requirement.Values,
+			})
+		}
+		result = append(result, map[string]interface{}{
+			"workload_label_selector": requirements,
+		})
+	}
+	return result
+}
+
+func resourceWorkloadGroupImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	config := m.(*Config)
+	client := config.Client()
+
+	// The import ID is the workload group name, the resource ID is the UUID assigned by PSM
+	name := d.Id()
+	url := config.Server + "/configs/workload/v1/tenant/default/workloadgroups/" + name
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.AddCookie(&http.Cookie{Name: "sid", Value: config.SID})
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to import workload group %s: HTTP %d %s: %s", name, resp.StatusCode, resp.Status, bodyBytes)
+	}
+
+	workloadgroup := &WorkloadGroup{}
+	if err := json.NewDecoder(resp.Body).Decode(workloadgroup); err != nil {
+		return nil, err
+	}
+
+	d.SetId(workloadgroup.Meta.UUID)
+	d.Set("name", workloadgroup.Meta.Name)
+
+	if diags := resourceWorkloadGroupRead(ctx, d, m); diags.HasError() {
+		return nil, fmt.Errorf("failed to read workload group %s: %s", name, diags[0].Summary)
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
 func resourceWorkloadGroupUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	config := m.(*Config)
 	client := config.Client()
@@ -246,7 +348,7 @@ func resourceWorkloadGroupUpdate(ctx context.Context, d *schema.ResourceData, m
 			reqMap := req.(map[string]interface{})
 			requirement := Requirement{
 				Key:      reqMap["workload_label_key"].(string),
-				Operator: reqMap["operator"].(string),
+				Operator: normalizeWorkloadSelectorOperator(reqMap["operator"].(string)),
 				Values:   convertInterfaceToStringSlice(reqMap["values"]),
 			}
 			requirements = append(requirements, requirement)
@@ -304,9 +406,9 @@ func resourceWorkloadGroupDelete(ctx context.Context, d *schema.ResourceData, m
 	client := config.Client()
 	workloadName := d.Get("name").(string)
 
-	// First, remove this workload group from all security policies
-	if err := removeWorkloadGroupFromPolicies(ctx, client, config, workloadName); err != nil {
-		return diag.FromErr(err)
+	// First, check that no security policy still references this workload group
+	if diags := checkPolicyReferences(ctx, d, config, referenceWorkloadGroup, workloadName); diags.HasError() {
+		return diags
 	}
 
 	// Then delete the workload group itself
@@ -331,129 +433,90 @@ func resourceWorkloadGroupDelete(ctx context.Context, d *schema.ResourceData, m
 	return nil
 }
 
-func removeWorkloadGroupFromPolicies(ctx context.Context, client *http.Client, config *Config, workloadName string) error {
-	req, err := http.NewRequestWithContext(ctx, "GET",
-		fmt.Sprintf("%s/configs/security/v1/tenant/default/networksecuritypolicies", config.Server), nil)
-	if err != nil {
-		return err
-	}
-
-	req.AddCookie(&http.Cookie{Name: "sid", Value: config.SID})
-	resp, err := client.Do(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	var policyList PolicyList
-	if err := json.NewDecoder(resp.Body).Decode(&policyList); err != nil {
-		return err
-	}
-
-	for _, item := range policyList.Items {
-		if err := updatePolicyWorkloadGroups(ctx, client, config, item.Meta.Name, workloadName); err != nil {
-			return err
+func removeString(slice []string, str string) []string {
+	newSlice := make([]string, 0)
+	for _, s := range slice {
+		if s != str {
+			newSlice = append(newSlice, s)
 		}
 	}
-
-	return nil
+	return newSlice
 }
 
-func updatePolicyWorkloadGroups(ctx context.Context, client *http.Client, config *Config, policyName, workloadName string) error {
-	// Get current policy
-	req, err := http.NewRequestWithContext(ctx, "GET",
-		fmt.Sprintf("%s/configs/security/v1/tenant/default/networksecuritypolicies/%s", config.Server, policyName), nil)
-	if err != nil {
-		return err
-	}
-
-	req.AddCookie(&http.Cookie{Name: "sid", Value: config.SID})
-	resp, err := client.Do(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	bodyBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to read response body: %v", err)
-	}
-
-	var policy NetworkSecurityPolicy
-	if err := json.Unmarshal(bodyBytes, &policy); err != nil {
-		return fmt.Errorf("failed to decode policy: %v", err)
-	}
-
-	// Store original metadata
-	originalKind := policy.Kind
-	originalAPIVersion := policy.APIVersion
-	originalMeta := policy.Meta
-	originalStatus := policy.Status
-
-	// Update rules
-	modified := false
-	newRules := make([]Rule, 0)
-	for _, rule := range policy.Spec.Rules {
-		if containsString(rule.FromWorkloadGroup, workloadName) || containsString(rule.ToWorkloadGroup, workloadName) {
-			modified = true
-			// Skip rules that would only reference the workload group being removed
-			if len(rule.ToWorkloadGroup) == 1 && rule.ToWorkloadGroup[0] == workloadName &&
-				len(rule.ToIPAddresses) == 0 && len(rule.ToIPCollections) == 0 {
-				continue
+// matchesWorkloadSelectors reports whether a workload with the given labels is a member of a group with the given
+// selectors. The requirements of a selector must all hold, and a workload matching any selector is a member.
+func matchesWorkloadSelectors(selectors []WorkloadSelector, labels map[string]string) bool {
+	for _, selector := range selectors {
+		if len(selector.Requirements) == 0 {
+			continue
+		}
+		matched := true
+		for _, requirement := range selector.Requirements {
+			if !requirement.matches(labels) {
+				matched = false
+				break
 			}
-			// Remove workload group references
-			rule.FromWorkloadGroup = removeString(rule.FromWorkloadGroup, workloadName)
-			rule.ToWorkloadGroup = removeString(rule.ToWorkloadGroup, workloadName)
 		}
-		newRules = append(newRules, rule)
-	}
-
-	if !modified {
-		return nil
+		if matched {
+			return true
+		}
 	}
+	return false
+}
 
-	policy.Spec.Rules = newRules
-
-	// Restore original metadata
-	policy.Kind = originalKind
-	policy.APIVersion = originalAPIVersion
-	policy.Meta = originalMeta
-	policy.Status = originalStatus
-
-	// Update policy
-	jsonBytes, err := json.Marshal(policy)
-	if err != nil {
-		return fmt.Errorf("failed to marshal updated policy: %v", err)
-	}
+// matches reports whether labels satisfy the requirement. Operators are compared in any case, and the existence
+// operators are understood as well, as groups created outside of Terraform are evaluated too.
+func (r Requirement) matches(labels map[string]string) bool {
+	value, ok := labels[r.Key]
+	switch strings.ToLower(r.Operator) {
+	case "equals", "in":
+		return ok && slices.Contains(r.Values, value)
+	case "notequals", "notin":
+		return !ok || !slices.Contains(r.Values, value)
+	case "exists":
+		return ok
+	case "doesnotexist":
+		return !ok
+	}
+	return false
+}
 
-	req, err = http.NewRequestWithContext(ctx, "PUT",
-		fmt.Sprintf("%s/configs/security/v1/tenant/default/networksecuritypolicies/%s", config.Server, policyName),
-		bytes.NewBuffer(jsonBytes))
-	if err != nil {
-		return err
+// resourceWorkloadGroupCustomizeDiff checks at plan time that every label requirement has the number of values its
+// operator expects: exactly one for equals and notEquals, at least one for in and notIn.
+func resourceWorkloadGroupCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !d.NewValueKnown("workload_selector") {
+		return nil
 	}
 
-	req.AddCookie(&http.Cookie{Name: "sid", Value: config.SID})
-	resp, err = client.Do(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
+	for i, ws := range d.Get("workload_selector").([]interface{}) {
+		wsMap, ok := ws.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for j, req := range wsMap["workload_label_selector"].([]interface{}) {
+			reqMap, ok := req.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			prefix := fmt.Sprintf("workload_selector.%d.workload_label_selector.%d", i, j)
+			if !d.NewValueKnown(prefix+".operator") || !d.NewValueKnown(prefix+".values") {
+				continue
+			}
 
-	if resp.StatusCode != http.StatusOK {
-		responseBody, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("failed to update policy: HTTP %d %s: %s", resp.StatusCode, resp.Status, string(responseBody))
+			operator := normalizeWorkloadSelectorOperator(reqMap["operator"].(string))
+			values := reqMap["values"].([]interface{})
+			switch operator {
+			case "equals", "notEquals":
+				if len(values) != 1 {
+					return fmt.Errorf("%s: operator %q takes exactly one value, got %d", prefix, operator, len(values))
+				}
+			case "in", "notIn":
+				if len(values) == 0 {
+					return fmt.Errorf("%s: operator %q takes at least one value", prefix, operator)
+				}
+			}
+		}
 	}
 
 	return nil
 }
-
-func removeString(slice []string, str string) []string {
-	newSlice := make([]string, 0)
-	for _, s := range slice {
-		if s != str {
-			newSlice = append(newSlice, s)
-		}
-	}
-	return newSlice
-}
@@ -0,0 +1,166 @@
+package psm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMatchesWorkloadSelectors(t *testing.T) {
+	labels := map[string]string{"tier": "web", "env": "prod"}
+	requirement := func(key, operator string, values ...string) Requirement {
+		return Requirement{Key: key, Operator: operator, Values: values}
+	}
+
+	tests := []struct {
+		name      string
+		selectors []WorkloadSelector
+		want      bool
+	}{
+		{"no selectors", nil, false},
+		{"empty selector", []WorkloadSelector{{}}, false},
+		{"equals", []WorkloadSelector{{Requirements: []Requirement{requirement("tier", "equals", "web")}}}, true},
+		{"equals other value", []WorkloadSelector{{Requirements: []Requirement{requirement("tier", "equals", "db")}}}, false},
+		{"equals missing label", []WorkloadSelector{{Requirements: []Requirement{requirement("zone", "equals", "a")}}}, false},
+		{"in", []WorkloadSelector{{Requirements: []Requirement{requirement("tier", "in", "db", "web")}}}, true},
+		{"notIn", []WorkloadSelector{{Requirements: []Requirement{requirement("tier", "notIn", "db", "web")}}}, false},
+		{"notEquals missing label", []WorkloadSelector{{Requirements: []Requirement{requirement("zone", "notEquals", "a")}}}, true},
+		{"operator in another case", []WorkloadSelector{{Requirements: []Requirement{requirement("tier", "NotEquals", "db")}}}, true},
+		{"exists", []WorkloadSelector{{Requirements: []Requirement{requirement("env", "exists")}}}, true},
+		{"doesNotExist", []WorkloadSelector{{Requirements: []Requirement{requirement("env", "doesNotExist")}}}, false},
+		{"unknown operator", []WorkloadSelector{{Requirements: []Requirement{requirement("tier", "like", "web")}}}, false},
+		{
+			name: "all requirements of a selector must hold",
+			selectors: []WorkloadSelector{{Requirements: []Requirement{
+				requirement("tier", "equals", "web"),
+				requirement("env", "equals", "dev"),
+			}}},
+			want: false,
+		},
+		{
+			name: "any selector may match",
+			selectors: []WorkloadSelector{
+				{Requirements: []Requirement{requirement("env", "equals", "dev")}},
+				{Requirements: []Requirement{requirement("env", "equals", "prod")}},
+			},
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchesWorkloadSelectors(tt.selectors, labels); got != tt.want {
+				t.Errorf("matchesWorkloadSelectors() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeWorkloadSelectorOperator(t *testing.T) {
+	tests := []struct {
+		operator string
+		want     string
+	}{
+		{"equals", "equals"},
+		{"Equals", "equals"},
+		{"NOTEQUALS", "notEquals"},
+		{"In", "in"},
+		{"notin", "notIn"},
+		{"Exists", "Exists"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := normalizeWorkloadSelectorOperator(tt.operator); got != tt.want {
+			t.Errorf("normalizeWorkloadSelectorOperator(%q) = %q, want %q", tt.operator, got, tt.want)
+		}
+	}
+}
+
+func TestValidateWorkloadSelectorOperator(t *testing.T) {
+	tests := []struct {
+		operator string
+		wantWarn bool
+		wantErr  string
+	}{
+		{operator: "equals"},
+		{operator: "notIn"},
+		{operator: "In", wantWarn: true},
+		{operator: "NotEquals", wantWarn: true},
+		{operator: "Exists", wantErr: "not supported by PSM"},
+		{operator: "doesnotexist", wantErr: "not supported by PSM"},
+		{operator: "like", wantErr: "expected operator to be one of"},
+	}
+
+	for _, tt := range tests {
+		warns, errs := validateWorkloadSelectorOperator(tt.operator, "operator")
+		if (len(warns) > 0) != tt.wantWarn {
+			t.Errorf("validateWorkloadSelectorOperator(%q) warnings = %q, want warning %v", tt.operator, warns, tt.wantWarn)
+		}
+		if tt.wantErr == "" && len(errs) > 0 {
+			t.Errorf("validateWorkloadSelectorOperator(%q) errors = %v", tt.operator, errs)
+		}
+		if tt.wantErr != "" && (len(errs) == 0 || !strings.Contains(errs[0].Error(), tt.wantErr)) {
+			t.Errorf("validateWorkloadSelectorOperator(%q) errors = %v, want %q", tt.operator, errs, tt.wantErr)
+		}
+	}
+}
+
+func TestSuppressEquivalentWorkloadSelectorOperator(t *testing.T) {
+	tests := []struct {
+		old, new string
+		want     bool
+	}{
+		{"in", "In", true},
+		{"notEquals", "NotEquals", true},
+		{"in", "notIn", false},
+		{"", "in", false},
+	}
+
+	for _, tt := range tests {
+		if got := suppressEquivalentWorkloadSelectorOperator("operator", tt.old, tt.new, nil); got != tt.want {
+			t.Errorf("suppressEquivalentWorkloadSelectorOperator(%q, %q) = %v, want %v", tt.old, tt.new, got, tt.want)
+		}
+	}
+}
+
+func TestResourceWorkloadGroupCustomizeDiff(t *testing.T) {
+	selector := func(operator string, values ...interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"name": "web",
+			"workload_selector": []interface{}{map[string]interface{}{
+				"workload_label_selector": []interface{}{map[string]interface{}{
+					"workload_label_key": "tier",
+					"operator":           operator,
+					"values":             values,
+				}},
+			}},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		config  map[string]interface{}
+		wantErr string
+	}{
+		{name: "equals one value", config: selector("equals", "web")},
+		{name: "equals two values", config: selector("equals", "web", "db"), wantErr: `operator "equals" takes exactly one value, got 2`},
+		{name: "deprecated spelling", config: selector("NotEquals"), wantErr: `operator "notEquals" takes exactly one value, got 0`},
+		{name: "in several values", config: selector("in", "web", "db")},
+		{name: "notIn without values", config: selector("notIn"), wantErr: `operator "notIn" takes at least one value`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := testPlan(t, resourceWorkloadGroup(), nil, tt.config, nil)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("plan error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("plan error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,288 @@
+package psm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// resourceWorkloadLabels manages a subset of the labels of a workload that is not managed by Terraform, such as a
+// workload created by an orchestrator. Labels set by others are left untouched.
+func resourceWorkloadLabels() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceWorkloadLabelsCreate,
+		ReadContext:   resourceWorkloadLabelsRead,
+		UpdateContext: resourceWorkloadLabelsUpdate,
+		DeleteContext: resourceWorkloadLabelsDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceWorkloadLabelsImport,
+		},
+		CustomizeDiff: resourceWorkloadLabelsCustomizeDiff,
+		Schema: map[string]*schema.Schema{
+			"workload": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Name of the workload to label",
+			},
+			"labels": {
+				Type:        schema.TypeMap,
+				Required:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Labels to set on the workload",
+			},
+			"managed_keys": {
+				Type:        schema.TypeSet,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Keys of the labels managed by this resource",
+			},
+		},
+	}
+}
+
+func resourceWorkloadLabelsCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	config := m.(*Config)
+	name := d.Get("workload").(string)
+	labels := expandStringMap(d.Get("labels").(map[string]interface{}))
+
+	err := updateWorkloadLabels(ctx, config, name, func(current map[string]interface{}) {
+		for k, v := range labels {
+			current[k] = v
+		}
+	})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(name)
+
+	return resourceWorkloadLabelsRead(ctx, d, m)
+}
+
+func resourceWorkloadLabelsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	config := m.(*Config)
+	client := config.Client()
+
+	url := fmt.Sprintf("%s/configs/workload/v1/tenant/default/workloads/%s", config.Server, d.Id())
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	req.AddCookie(&http.Cookie{Name: "sid", Value: config.SID})
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		d.SetId("")
+		return nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return diag.Errorf("failed to read workload %s: HTTP %d %s: %s", d.Id(), resp.StatusCode, resp.Status, bodyBytes)
+	}
+
+	workload := &Workload{}
+	if err := json.NewDecoder(resp.Body).Decode(workload); err != nil {
+		return diag.FromErr(err)
+	}
+
+	// Only the labels managed by this resource are tracked, other labels of the workload are not drift
+	managed := workloadLabelsManagedKeys(d)
+	labels := make(map[string]string)
+	for k, v := range workload.Meta.Labels {
+		if containsString(managed, k) {
+			labels[k] = v
+		}
+	}
+
+	d.Set("workload", workload.Meta.Name)
+	d.Set("labels", labels)
+	d.Set("managed_keys", managed)
+
+	return nil
+}
+
+func resourceWorkloadLabelsUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	config := m.(*Config)
+
+	before, after := d.GetChange("labels")
+	removed := before.(map[string]interface{})
+	labels := expandStringMap(after.(map[string]interface{}))
+
+	err := updateWorkloadLabels(ctx, config, d.Id(), func(current map[string]interface{}) {
+		for k := range removed {
+			delete(current, k)
+		}
+		for k, v := range labels {
+			current[k] = v
+		}
+	})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return resourceWorkloadLabelsRead(ctx, d, m)
+}
+
+func resourceWorkloadLabelsDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	config := m.(*Config)
+	managed := workloadLabelsManagedKeys(d)
+
+	err := updateWorkloadLabels(ctx, config, d.Id(), func(current map[string]interface{}) {
+		for _, k := range managed {
+			delete(current, k)
+		}
+	})
+	if err != nil && !errors.Is(err, errWorkloadNotFound) {
+		return diag.FromErr(err)
+	}
+
+	d.SetId("")
+
+	return nil
+}
+
+// resourceWorkloadLabelsImport takes over every label the workload has when it is imported.
+func resourceWorkloadLabelsImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	config := m.(*Config)
+
+	url := fmt.Sprintf("%s/configs/workload/v1/tenant/default/workloads/%s", config.Server, d.Id())
+	body, err := doNetworkRequest(ctx, config, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to import labels of workload %s: %w", d.Id(), err)
+	}
+
+	workload := &Workload{}
+	if err := json.Unmarshal(body, workload); err != nil {
+		return nil, err
+	}
+
+	managed := make([]string, 0, len(workload.Meta.Labels))
+	for k := range workload.Meta.Labels {
+		managed = append(managed, k)
+	}
+	d.Set("managed_keys", managed)
+
+	return []*schema.ResourceData{d}, nil
+}
+
+// resourceWorkloadLabelsCustomizeDiff plans managed_keys as the keys of the configured labels, so that the labels
+// managed by the resource are known even when labels is empty.
+func resourceWorkloadLabelsCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !d.NewValueKnown("labels") {
+		return d.SetNewComputed("managed_keys")
+	}
+
+	labels := d.Get("labels").(map[string]interface{})
+	keys := make([]interface{}, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	if !d.Get("managed_keys").(*schema.Set).Equal(schema.NewSet(schema.HashString, keys)) {
+		return d.SetNew("managed_keys", keys)
+	}
+	return nil
+}
+
+// workloadLabelsManagedKeys returns the keys of the labels managed by the resource. State written before
+// managed_keys existed only has the managed labels themselves.
+func workloadLabelsManagedKeys(d *schema.ResourceData) []string {
+	if managed, ok := d.GetOk("managed_keys"); ok {
+		return ExpandStringSet(managed.(*schema.Set))
+	}
+	var keys []string
+	for k := range d.Get("labels").(map[string]interface{}) {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+var errWorkloadNotFound = errors.New("workload not found")
+
+// updateWorkloadLabels applies update to the labels of a workload. The workload is handled as a raw object so that
+// fields the provider does not model are written back unchanged, and the update is retried once if the workload
+// changed between reading and writing it.
+func updateWorkloadLabels(ctx context.Context, config *Config, name string, update func(labels map[string]interface{})) error {
+	client := config.Client()
+	url := fmt.Sprintf("%s/configs/workload/v1/tenant/default/workloads/%s", config.Server, name)
+
+	for attempt := 1; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		if err != nil {
+			return err
+		}
+		req.AddCookie(&http.Cookie{Name: "sid", Value: config.SID})
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode == http.StatusNotFound {
+			return fmt.Errorf("failed to update labels of workload %s: %w", name, errWorkloadNotFound)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("failed to read workload %s: HTTP %d %s: %s", name, resp.StatusCode, resp.Status, bodyBytes)
+		}
+
+		workload := make(map[string]interface{})
+		if err := json.Unmarshal(bodyBytes, &workload); err != nil {
+			return err
+		}
+		meta, _ := workload["meta"].(map[string]interface{})
+		if meta == nil {
+			meta = make(map[string]interface{})
+			workload["meta"] = meta
+		}
+		labels, _ := meta["labels"].(map[string]interface{})
+		if labels == nil {
+			labels = make(map[string]interface{})
+		}
+		update(labels)
+		meta["labels"] = labels
+
+		jsonBytes, err := json.Marshal(workload)
+		if err != nil {
+			return err
+		}
+
+		req, err = http.NewRequestWithContext(ctx, "PUT", url, bytes.NewBuffer(jsonBytes))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.AddCookie(&http.Cookie{Name: "sid", Value: config.SID})
+
+		resp, err = client.Do(req)
+		if err != nil {
+			return err
+		}
+		bodyBytes, _ = io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode == http.StatusConflict && attempt == 1 {
+			log.Printf("[DEBUG] Workload %s changed while updating its labels, retrying", name)
+			continue
+		}
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("failed to update labels of workload %s: HTTP %d %s: %s", name, resp.StatusCode, resp.Status, bodyBytes)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,190 @@
+package psm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestResourceWorkloadLabelsRead(t *testing.T) {
+	config := newTestServer(t, map[string]string{
+		"/configs/workload/v1/tenant/default/workloads/vm1": `{
+			"meta": {"name": "vm1", "labels": {"tier": "web", "env": "prod", "owner": "ops"}},
+			"spec": {"host-name": "esx1"}
+		}`,
+	})
+
+	tests := []struct {
+		name       string
+		state      map[string]string
+		wantLabels map[string]interface{}
+		wantKeys   []string
+	}{
+		{
+			name: "managed keys",
+			state: map[string]string{
+				"workload":       "vm1",
+				"labels.%":       "1",
+				"labels.tier":    "web",
+				"managed_keys.#": "2",
+				"managed_keys." + strconv.Itoa(hashString("tier")): "tier",
+				"managed_keys." + strconv.Itoa(hashString("env")):  "env",
+			},
+			wantLabels: map[string]interface{}{"tier": "web", "env": "prod"},
+			wantKeys:   []string{"env", "tier"},
+		},
+		{
+			name:       "state without managed keys",
+			state:      map[string]string{"workload": "vm1", "labels.%": "1", "labels.tier": "app"},
+			wantLabels: map[string]interface{}{"tier": "web"},
+			wantKeys:   []string{"tier"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := resourceWorkloadLabels()
+			d := r.Data(&terraform.InstanceState{ID: "vm1", Attributes: tt.state})
+
+			if diags := resourceWorkloadLabelsRead(context.Background(), d, config); diags.HasError() {
+				t.Fatalf("read error = %v", diags)
+			}
+
+			if got := d.Get("labels"); !reflect.DeepEqual(got, tt.wantLabels) {
+				t.Errorf("labels = %v, want %v", got, tt.wantLabels)
+			}
+			keys := ExpandStringSet(d.Get("managed_keys").(*schema.Set))
+			sort.Strings(keys)
+			if !reflect.DeepEqual(keys, tt.wantKeys) {
+				t.Errorf("managed_keys = %q, want %q", keys, tt.wantKeys)
+			}
+		})
+	}
+}
+
+func TestResourceWorkloadLabelsCustomizeDiff(t *testing.T) {
+	state := map[string]string{
+		"id":             "vm1",
+		"workload":       "vm1",
+		"labels.%":       "1",
+		"labels.tier":    "web",
+		"managed_keys.#": "1",
+		"managed_keys." + strconv.Itoa(hashString("tier")): "tier",
+	}
+
+	tests := []struct {
+		name     string
+		labels   map[string]interface{}
+		wantKeys []string
+	}{
+		{"unchanged", map[string]interface{}{"tier": "web"}, []string{"tier"}},
+		{"label added", map[string]interface{}{"tier": "web", "env": "prod"}, []string{"env", "tier"}},
+		{"value changed", map[string]interface{}{"tier": "app"}, []string{"tier"}},
+		{"all labels removed", map[string]interface{}{}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := resourceWorkloadLabels()
+			diff, err := testPlan(t, r, state, map[string]interface{}{"workload": "vm1", "labels": tt.labels}, nil)
+			if err != nil {
+				t.Fatalf("plan error = %v", err)
+			}
+
+			keys := ExpandStringSet(plannedData(t, r, state, diff).Get("managed_keys").(*schema.Set))
+			sort.Strings(keys)
+			if len(keys) == 0 {
+				keys = nil
+			}
+			if !reflect.DeepEqual(keys, tt.wantKeys) {
+				t.Errorf("managed_keys = %q, want %q", keys, tt.wantKeys)
+			}
+		})
+	}
+}
+
+func TestUpdateWorkloadLabels(t *testing.T) {
+	workload := `{"meta": {"name": "vm1", "labels": {"tier": "web", "owner": "ops"}}, "spec": {"host-name": "esx1", "unmodelled": {"kept": true}}}`
+	var puts []map[string]interface{}
+	conflicts := 1
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/configs/workload/v1/tenant/default/workloads/vm1" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Method == http.MethodGet {
+			w.Write([]byte(workload))
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		put := make(map[string]interface{})
+		json.Unmarshal(body, &put)
+		puts = append(puts, put)
+		if conflicts > 0 {
+			conflicts--
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		w.Write(body)
+	}))
+	defer server.Close()
+	config := &Config{Server: server.URL}
+
+	err := updateWorkloadLabels(context.Background(), config, "vm1", func(labels map[string]interface{}) {
+		delete(labels, "tier")
+		labels["env"] = "prod"
+	})
+	if err != nil {
+		t.Fatalf("updateWorkloadLabels() error = %v", err)
+	}
+
+	if len(puts) != 2 {
+		t.Fatalf("PUT requests = %d, want 2 as the first one conflicts", len(puts))
+	}
+	meta := puts[1]["meta"].(map[string]interface{})
+	if want := map[string]interface{}{"env": "prod", "owner": "ops"}; !reflect.DeepEqual(meta["labels"], want) {
+		t.Errorf("labels = %v, want %v", meta["labels"], want)
+	}
+	spec := puts[1]["spec"].(map[string]interface{})
+	if _, ok := spec["unmodelled"]; !ok {
+		t.Errorf("spec = %v, fields the provider does not model were dropped", spec)
+	}
+
+	// A second conflict is not retried
+	conflicts = 2
+	if err := updateWorkloadLabels(context.Background(), config, "vm1", func(map[string]interface{}) {}); err == nil {
+		t.Errorf("updateWorkloadLabels() error = nil, want the conflict")
+	}
+
+	err = updateWorkloadLabels(context.Background(), config, "missing", func(map[string]interface{}) {})
+	if !errors.Is(err, errWorkloadNotFound) {
+		t.Errorf("updateWorkloadLabels() error = %v, want errWorkloadNotFound", err)
+	}
+}
+
+func TestResourceWorkloadLabelsImport(t *testing.T) {
+	config := newTestServer(t, map[string]string{
+		"/configs/workload/v1/tenant/default/workloads/vm1": `{"meta": {"name": "vm1", "labels": {"tier": "web", "env": "prod"}}}`,
+	})
+
+	d := resourceWorkloadLabels().Data(&terraform.InstanceState{ID: "vm1"})
+	if _, err := resourceWorkloadLabelsImport(context.Background(), d, config); err != nil {
+		t.Fatalf("import error = %v", err)
+	}
+
+	keys := ExpandStringSet(d.Get("managed_keys").(*schema.Set))
+	sort.Strings(keys)
+	if want := []string{"env", "tier"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("managed_keys = %q, want every label of the workload %q", keys, want)
+	}
+}
@@ -0,0 +1,501 @@
+package psm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/id"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// resourceWorkloadSet manages many workloads as a single resource, for inventories of bare-metal servers that would
+// otherwise need thousands of psm_workload resources. Workloads are reconciled concurrently and a workload that
+// cannot be created, updated or deleted is reported without failing the rest of the set.
+func resourceWorkloadSet() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceWorkloadSetCreate,
+		ReadContext:   resourceWorkloadSetRead,
+		UpdateContext: resourceWorkloadSetUpdate,
+		DeleteContext: resourceWorkloadSetDelete,
+		CustomizeDiff: resourceWorkloadSetCustomizeDiff,
+		Schema: map[string]*schema.Schema{
+			"workload": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Set:         workloadSetHash,
+				Description: "Workloads managed by the set, identified by name",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"host_name": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"labels": {
+							Type:     schema.TypeMap,
+							Optional: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"interface": {
+							Type:     schema.TypeList,
+							Required: true,
+							Elem:     workloadInterfaceResource(),
+						},
+					},
+				},
+			},
+			"parallelism": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      8,
+				ValidateFunc: validation.IntBetween(1, 64),
+				Description:  "Maximum number of concurrent requests to PSM",
+			},
+			"adopt_existing": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Update workloads that already exist in PSM with the same name instead of reporting them as failed",
+			},
+			"workload_hashes": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Hash of each workload of the set by name, so that a plan lists the workloads that are added, changed or removed",
+			},
+			"failed_workloads": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Workloads that could not be reconciled during the last apply, with the error PSM returned",
+			},
+		},
+	}
+}
+
+// workloadSetAction is what needs to happen to a single workload of a set.
+type workloadSetAction string
+
+const (
+	workloadSetCreate workloadSetAction = "create"
+	workloadSetUpdate workloadSetAction = "update"
+	workloadSetDelete workloadSetAction = "delete"
+)
+
+type workloadSetOp struct {
+	action   workloadSetAction
+	name     string
+	workload *Workload
+	// adopt makes a create update a workload of the same name that already exists in PSM
+	adopt bool
+}
+
+func resourceWorkloadSetCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	d.SetId(id.UniqueId())
+	return resourceWorkloadSetApply(ctx, d, m, nil)
+}
+
+func resourceWorkloadSetUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	before, _ := d.GetChange("workload")
+	return resourceWorkloadSetApply(ctx, d, m, before.(*schema.Set))
+}
+
+// resourceWorkloadSetApply reconciles PSM with the planned workloads, given the workloads previously in state.
+// Deletions run first so that MAC and IP addresses they free can be reused by the workloads created after them.
+func resourceWorkloadSetApply(ctx context.Context, d *schema.ResourceData, m interface{}, before *schema.Set) diag.Diagnostics {
+	config := m.(*Config)
+	parallelism := d.Get("parallelism").(int)
+	adoptExisting := d.Get("adopt_existing").(bool)
+
+	oldItems := workloadSetItems(before)
+	newItems := workloadSetItems(d.Get("workload").(*schema.Set))
+
+	var deletes, upserts []workloadSetOp
+	for name := range oldItems {
+		if _, ok := newItems[name]; !ok {
+			deletes = append(deletes, workloadSetOp{action: workloadSetDelete, name: name})
+		}
+	}
+	for name, item := range newItems {
+		workload := expandWorkloadSetItem(item)
+		old, ok := oldItems[name]
+		switch {
+		case !ok:
+			upserts = append(upserts, workloadSetOp{action: workloadSetCreate, name: name, workload: workload, adopt: adoptExisting})
+		case workloadSetHash(old) != workloadSetHash(item):
+			upserts = append(upserts, workloadSetOp{action: workloadSetUpdate, name: name, workload: workload})
+		}
+	}
+
+	log.Printf("[INFO] Reconciling workload set %s: %d to delete, %d to create or update", d.Id(), len(deletes), len(upserts))
+
+	failed := runWorkloadSetOps(ctx, config, parallelism, deletes)
+	for name, err := range runWorkloadSetOps(ctx, config, parallelism, upserts) {
+		failed[name] = err
+	}
+
+	// Failed workloads keep their previous state, so that the next plan tries them again
+	state := make(map[string]map[string]interface{}, len(newItems))
+	for name, item := range newItems {
+		state[name] = item
+	}
+	for name := range failed {
+		if old, ok := oldItems[name]; ok {
+			state[name] = old
+		} else {
+			delete(state, name)
+		}
+	}
+
+	if err := d.Set("workload", workloadSetList(state)); err != nil {
+		return diag.FromErr(err)
+	}
+	failures := make(map[string]string, len(failed))
+	for name, err := range failed {
+		failures[name] = err.Error()
+	}
+	d.Set("failed_workloads", failures)
+
+	diags := workloadSetFailureDiagnostics(failed, len(deletes)+len(upserts), diag.Warning)
+	return append(diags, resourceWorkloadSetRead(ctx, d, m)...)
+}
+
+func resourceWorkloadSetRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	config := m.(*Config)
+
+	// A single listing refreshes the whole set instead of one request per workload
+	workloads, err := listWorkloads(ctx, config)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	current := make(map[string]*Workload, len(workloads))
+	for i := range workloads {
+		current[workloads[i].Meta.Name] = &workloads[i]
+	}
+
+	state := make(map[string]map[string]interface{})
+	for name := range workloadSetItems(d.Get("workload").(*schema.Set)) {
+		workload, ok := current[name]
+		if !ok {
+			log.Printf("[DEBUG] Workload %s of workload set %s no longer exists", name, d.Id())
+			continue
+		}
+		state[name] = flattenWorkloadSetItem(workload)
+	}
+
+	if err := d.Set("workload", workloadSetList(state)); err != nil {
+		return diag.FromErr(err)
+	}
+	d.Set("workload_hashes", workloadSetHashes(state))
+
+	return nil
+}
+
+func resourceWorkloadSetDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	config := m.(*Config)
+
+	items := workloadSetItems(d.Get("workload").(*schema.Set))
+	ops := make([]workloadSetOp, 0, len(items))
+	for name := range items {
+		ops = append(ops, workloadSetOp{action: workloadSetDelete, name: name})
+	}
+
+	failed := runWorkloadSetOps(ctx, config, d.Get("parallelism").(int), ops)
+	if len(failed) > 0 {
+		// Keep the workloads that are left so that destroying the set can be retried
+		remaining := make(map[string]map[string]interface{}, len(failed))
+		for name := range failed {
+			remaining[name] = items[name]
+		}
+		d.Set("workload", workloadSetList(remaining))
+		return workloadSetFailureDiagnostics(failed, len(ops), diag.Error)
+	}
+
+	d.SetId("")
+
+	return nil
+}
+
+// resourceWorkloadSetCustomizeDiff rejects duplicate workload names and MAC addresses shared by interfaces of a
+// workload, and marks failed_workloads for recomputation whenever the set changes. workload_hashes is planned from
+// the configured workloads: as set elements have no identity, a changed workload shows up in the plan as one
+// element removed and another added, and workload_hashes is what names the workloads that change.
+func resourceWorkloadSetCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if d.HasChange("workload") {
+		if err := d.SetNewComputed("failed_workloads"); err != nil {
+			return err
+		}
+	}
+	if !d.NewValueKnown("workload") {
+		return d.SetNewComputed("workload_hashes")
+	}
+
+	seen := make(map[string]bool)
+	for _, v := range d.Get("workload").(*schema.Set).List() {
+		item := v.(map[string]interface{})
+		name := item["name"].(string)
+		if seen[name] {
+			return fmt.Errorf("workload %s is defined more than once", name)
+		}
+		seen[name] = true
+
+		err := checkUniqueMACAddresses(item["interface"].([]interface{}), func(int) bool { return true })
+		if err != nil {
+			return fmt.Errorf("workload %s: %w", name, err)
+		}
+	}
+
+	hashes := workloadSetHashes(workloadSetItems(d.Get("workload").(*schema.Set)))
+	if !reflect.DeepEqual(d.Get("workload_hashes"), hashes) {
+		return d.SetNew("workload_hashes", hashes)
+	}
+	return nil
+}
+
+// runWorkloadSetOps runs the operations with at most parallelism requests in flight and returns the errors of the
+// operations that failed, by workload name.
+func runWorkloadSetOps(ctx context.Context, config *Config, parallelism int, ops []workloadSetOp) map[string]error {
+	failed := make(map[string]error)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, parallelism)
+
+	for _, op := range ops {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			mu.Lock()
+			failed[op.name] = ctx.Err()
+			mu.Unlock()
+			continue
+		}
+
+		wg.Add(1)
+		go func(op workloadSetOp) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if err := runWorkloadSetOp(ctx, config, op); err != nil {
+				log.Printf("[WARN] Failed to %s workload %s: %s", op.action, op.name, err)
+				mu.Lock()
+				failed[op.name] = err
+				mu.Unlock()
+			}
+		}(op)
+	}
+	wg.Wait()
+
+	return failed
+}
+
+func runWorkloadSetOp(ctx context.Context, config *Config, op workloadSetOp) error {
+	collectionURL := config.Server + "/configs/workload/v1/tenant/default/workloads"
+	url := collectionURL + "/" + op.name
+
+	switch op.action {
+	case workloadSetCreate:
+		status, body, err := doWorkloadSetRequest(ctx, config, "POST", collectionURL, op.workload)
+		if err != nil {
+			return err
+		}
+		// A workload registered outside of Terraform with the same name is only overwritten if adopt_existing is set
+		if status == http.StatusConflict {
+			if !op.adopt {
+				return fmt.Errorf("workload %s already exists in PSM and is not managed by this set, set adopt_existing = true to take it over", op.name)
+			}
+			log.Printf("[INFO] Adopting existing workload %s", op.name)
+			return runWorkloadSetOp(ctx, config, workloadSetOp{action: workloadSetUpdate, name: op.name, workload: op.workload})
+		}
+		if status != http.StatusOK {
+			return fmt.Errorf("failed to create workload %s: HTTP %d %s: %s", op.name, status, http.StatusText(status), body)
+		}
+	case workloadSetUpdate:
+		status, body, err := doWorkloadSetRequest(ctx, config, "PUT", url, op.workload)
+		if err != nil {
+			return err
+		}
+		if status != http.StatusOK {
+			return fmt.Errorf("failed to update workload %s: HTTP %d %s: %s", op.name, status, http.StatusText(status), body)
+		}
+	case workloadSetDelete:
+		status, body, err := doWorkloadSetRequest(ctx, config, "DELETE", url, nil)
+		if err != nil {
+			return err
+		}
+		if status != http.StatusOK && status != http.StatusNoContent && status != http.StatusNotFound {
+			return fmt.Errorf("failed to delete workload %s: HTTP %d %s: %s", op.name, status, http.StatusText(status), body)
+		}
+	}
+	return nil
+}
+
+func doWorkloadSetRequest(ctx context.Context, config *Config, method, url string, workload *Workload) (int, []byte, error) {
+	var body io.Reader
+	if workload != nil {
+		jsonBytes, err := json.Marshal(workload)
+		if err != nil {
+			return 0, nil, err
+		}
+		body = bytes.NewBuffer(jsonBytes)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.AddCookie(&http.Cookie{Name: "sid", Value: config.SID})
+
+	resp, err := config.Client().Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+
+	bodyBytes, err := io.ReadAll(resp.Body)
+	return resp.StatusCode, bodyBytes, err
+}
+
+// workloadSetFailureDiagnostics summarises the failed workloads of a set in a single diagnostic.
+func workloadSetFailureDiagnostics(failed map[string]error, total int, severity diag.Severity) diag.Diagnostics {
+	if len(failed) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(failed))
+	for name := range failed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var detail strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&detail, "%s: %s\n", name, failed[name])
+	}
+
+	return diag.Diagnostics{{
+		Severity: severity,
+		Summary:  fmt.Sprintf("%d of %d workloads could not be reconciled", len(failed), total),
+		Detail:   detail.String(),
+	}}
+}
+
+// workloadSetItems indexes the workloads of a set by name.
+func workloadSetItems(set *schema.Set) map[string]map[string]interface{} {
+	items := make(map[string]map[string]interface{})
+	if set == nil {
+		return items
+	}
+	for _, v := range set.List() {
+		item := v.(map[string]interface{})
+		items[item["name"].(string)] = item
+	}
+	return items
+}
+
+func workloadSetList(items map[string]map[string]interface{}) []interface{} {
+	list := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		list = append(list, item)
+	}
+	return list
+}
+
+func expandWorkloadSetItem(item map[string]interface{}) *Workload {
+	workload := &Workload{
+		Kind:       "Workload",
+		APIVersion: "v1",
+	}
+	workload.Meta.Name = item["name"].(string)
+	workload.Meta.Namespace = "default"
+	workload.Meta.Tenant = "default"
+	if labels, ok := item["labels"].(map[string]interface{}); ok {
+		workload.Meta.Labels = expandStringMap(labels)
+	}
+	workload.Spec.HostName = item["host_name"].(string)
+	workload.Spec.MigrationTimeout = "60s"
+	if interfaces, ok := item["interface"].([]interface{}); ok {
+		// Interfaces of a set element being planned can still be nil
+		workload.Spec.Interfaces = expandWorkloadInterfaces(slices.DeleteFunc(slices.Clone(interfaces), func(v interface{}) bool {
+			return v == nil
+		}))
+	}
+	return workload
+}
+
+// flattenWorkloadSetItem returns a workload in the shape the SDK stores set elements in, which workloadSetHash
+// relies on to hash workloads read from PSM and from the configuration alike.
+func flattenWorkloadSetItem(workload *Workload) map[string]interface{} {
+	labels := make(map[string]interface{}, len(workload.Meta.Labels))
+	for k, v := range workload.Meta.Labels {
+		labels[k] = v
+	}
+
+	interfaces := flattenWorkloadInterfaces(workload.Spec.Interfaces)
+	for _, iface := range interfaces {
+		ifaceMap := iface.(map[string]interface{})
+		ipAddresses := make([]interface{}, 0)
+		for _, ip := range ifaceMap["ip_addresses"].([]string) {
+			ipAddresses = append(ipAddresses, ip)
+		}
+		ifaceMap["ip_addresses"] = ipAddresses
+		for key, zero := range map[string]interface{}{"micro_seg_vlan": 0, "network": "", "vni": 0} {
+			if _, ok := ifaceMap[key]; !ok {
+				ifaceMap[key] = zero
+			}
+		}
+	}
+
+	return map[string]interface{}{
+		"name":      workload.Meta.Name,
+		"host_name": workload.Spec.HostName,
+		"labels":    labels,
+		"interface": interfaces,
+	}
+}
+
+// workloadSetHashes returns the hash of each workload by name, as planned in workload_hashes.
+func workloadSetHashes(items map[string]map[string]interface{}) map[string]interface{} {
+	hashes := make(map[string]interface{}, len(items))
+	for name, item := range items {
+		hashes[name] = strconv.Itoa(workloadSetHash(item))
+	}
+	return hashes
+}
+
+// workloadSetHash hashes a workload as it is sent to PSM, so that MAC addresses written in different notations do
+// not cause a diff.
+func workloadSetHash(v interface{}) int {
+	workload := expandWorkloadSetItem(v.(map[string]interface{}))
+	// Attributes left unset hash the same whether PSM omits them or returns their zero value
+	for i := range workload.Spec.Interfaces {
+		iface := &workload.Spec.Interfaces[i]
+		if iface.MicroSegVlan != nil && *iface.MicroSegVlan == 0 {
+			iface.MicroSegVlan = nil
+		}
+		if iface.Network != nil && *iface.Network == "" {
+			iface.Network = nil
+		}
+		if iface.Vni != nil && *iface.Vni == 0 {
+			iface.Vni = nil
+		}
+	}
+	jsonBytes, _ := json.Marshal(workload)
+	return schema.HashString(string(jsonBytes))
+}
@@ -0,0 +1,209 @@
+package psm
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// workloadSetItem returns a workload of a set as configured, with a single interface.
+func workloadSetItem(name, host, mac string) map[string]interface{} {
+	return map[string]interface{}{
+		"name":      name,
+		"host_name": host,
+		"labels":    map[string]interface{}{"tier": "web"},
+		"interface": []interface{}{map[string]interface{}{
+			"mac_address":    mac,
+			"external_vlan":  100,
+			"ip_addresses":   []interface{}{"10.0.0.10"},
+			"micro_seg_vlan": 0,
+			"network":        "",
+			"vni":            0,
+		}},
+	}
+}
+
+func TestWorkloadSetHash(t *testing.T) {
+	item := workloadSetItem("web01", "esx1", "0011.2233.4455")
+
+	// The same workload as read back from PSM, which leaves out unset attributes
+	fromPSM := flattenWorkloadSetItem(expandWorkloadSetItem(workloadSetItem("web01", "esx1", "00:11:22:33:44:55")))
+
+	otherLabels := workloadSetItem("web01", "esx1", "0011.2233.4455")
+	otherLabels["labels"] = map[string]interface{}{"tier": "db"}
+
+	otherVNI := workloadSetItem("web01", "esx1", "0011.2233.4455")
+	otherVNI["interface"].([]interface{})[0].(map[string]interface{})["vni"] = 5000
+
+	tests := []struct {
+		name  string
+		other map[string]interface{}
+		equal bool
+	}{
+		{"same workload", workloadSetItem("web01", "esx1", "0011.2233.4455"), true},
+		{"MAC address in another notation", workloadSetItem("web01", "esx1", "00-11-22-33-44-55"), true},
+		{"read back from PSM", fromPSM, true},
+		{"other name", workloadSetItem("web02", "esx1", "0011.2233.4455"), false},
+		{"other host", workloadSetItem("web01", "esx2", "0011.2233.4455"), false},
+		{"other MAC address", workloadSetItem("web01", "esx1", "0011.2233.4456"), false},
+		{"other labels", otherLabels, false},
+		{"VNI set", otherVNI, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := workloadSetHash(tt.other) == workloadSetHash(item); got != tt.equal {
+				t.Errorf("hashes equal = %v, want %v", got, tt.equal)
+			}
+		})
+	}
+}
+
+// workloadSetServer is a fake PSM holding workloads, which records the requests changing them and fails to create
+// the workloads named in fail.
+type workloadSetServer struct {
+	mu        sync.Mutex
+	workloads map[string]*Workload
+	fail      map[string]bool
+	requests  []string
+}
+
+func (s *workloadSetServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	name := path.Base(r.URL.Path)
+	workload := &Workload{}
+	if body, _ := io.ReadAll(r.Body); len(body) > 0 {
+		json.Unmarshal(body, workload)
+		name = workload.Meta.Name
+	}
+	if r.Method != http.MethodGet {
+		s.requests = append(s.requests, r.Method+" "+name)
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		list := struct {
+			Items []*Workload `json:"items"`
+		}{}
+		for _, workload := range s.workloads {
+			list.Items = append(list.Items, workload)
+		}
+		json.NewEncoder(w).Encode(list)
+	case http.MethodPost:
+		if s.fail[name] {
+			http.Error(w, "invalid workload", http.StatusInternalServerError)
+			return
+		}
+		if _, ok := s.workloads[name]; ok {
+			http.Error(w, "already exists", http.StatusConflict)
+			return
+		}
+		s.workloads[name] = workload
+	case http.MethodPut:
+		s.workloads[name] = workload
+	case http.MethodDelete:
+		delete(s.workloads, name)
+	}
+}
+
+func TestResourceWorkloadSetApply(t *testing.T) {
+	before := []map[string]interface{}{
+		workloadSetItem("keep", "esx1", "0000.0000.0001"),
+		workloadSetItem("move", "esx1", "0000.0000.0002"),
+		workloadSetItem("remove", "esx1", "0000.0000.0003"),
+	}
+	after := []interface{}{
+		workloadSetItem("keep", "esx1", "00:00:00:00:00:01"),
+		workloadSetItem("move", "esx2", "0000.0000.0002"),
+		workloadSetItem("add", "esx1", "0000.0000.0004"),
+		workloadSetItem("invalid", "esx1", "0000.0000.0005"),
+		workloadSetItem("unmanaged", "esx1", "0000.0000.0006"),
+	}
+
+	fake := &workloadSetServer{
+		workloads: map[string]*Workload{"unmanaged": expandWorkloadSetItem(workloadSetItem("unmanaged", "esx9", "0000.0000.0006"))},
+		fail:      map[string]bool{"invalid": true},
+	}
+	beforeSet := schema.NewSet(workloadSetHash, nil)
+	for _, item := range before {
+		fake.workloads[item["name"].(string)] = expandWorkloadSetItem(item)
+		beforeSet.Add(item)
+	}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	d := schema.TestResourceDataRaw(t, resourceWorkloadSet().Schema, map[string]interface{}{"workload": after})
+	d.SetId("set")
+	diags := resourceWorkloadSetApply(context.Background(), d, &Config{Server: server.URL}, beforeSet)
+
+	// Unchanged workloads, whatever the notation of their MAC address, cause no request
+	sort.Strings(fake.requests)
+	wantRequests := []string{"DELETE remove", "POST add", "POST invalid", "POST unmanaged", "PUT move"}
+	if !reflect.DeepEqual(fake.requests, wantRequests) {
+		t.Errorf("requests = %q, want %q", fake.requests, wantRequests)
+	}
+
+	if len(diags) != 1 || diags[0].Severity != diag.Warning || diags[0].Summary != "2 of 5 workloads could not be reconciled" {
+		t.Fatalf("diagnostics = %v, want a single warning for the failed workloads", diags)
+	}
+
+	failed := d.Get("failed_workloads").(map[string]interface{})
+	if len(failed) != 2 || !strings.Contains(failed["invalid"].(string), "HTTP 500") || !strings.Contains(failed["unmanaged"].(string), "adopt_existing") {
+		t.Errorf("failed_workloads = %v", failed)
+	}
+
+	// Workloads that failed to be created are left out of state, so that the next plan creates them again
+	var names []string
+	for name := range workloadSetItems(d.Get("workload").(*schema.Set)) {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if want := []string{"add", "keep", "move"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("workloads in state = %q, want %q", names, want)
+	}
+	if got := fake.workloads["move"].Spec.HostName; got != "esx2" {
+		t.Errorf("host of moved workload = %q, want esx2", got)
+	}
+
+	hashes := d.Get("workload_hashes").(map[string]interface{})
+	if len(hashes) != 3 || hashes["keep"] == "" {
+		t.Errorf("workload_hashes = %v, want a hash for each workload in state", hashes)
+	}
+}
+
+func TestResourceWorkloadSetCustomizeDiff(t *testing.T) {
+	r := resourceWorkloadSet()
+
+	diff, err := testPlan(t, r, nil, map[string]interface{}{"workload": []interface{}{
+		workloadSetItem("web01", "esx1", "0011.2233.4455"),
+		workloadSetItem("web02", "esx1", "0011.2233.4456"),
+	}}, nil)
+	if err != nil {
+		t.Fatalf("plan error = %v", err)
+	}
+	hashes := plannedData(t, r, nil, diff).Get("workload_hashes").(map[string]interface{})
+	if len(hashes) != 2 || hashes["web01"] == "" || hashes["web02"] == "" || hashes["web01"] == hashes["web02"] {
+		t.Errorf("workload_hashes = %v, want a distinct hash for web01 and web02", hashes)
+	}
+
+	_, err = testPlan(t, r, nil, map[string]interface{}{"workload": []interface{}{
+		workloadSetItem("web01", "esx1", "0011.2233.4455"),
+		workloadSetItem("web01", "esx2", "0011.2233.4455"),
+	}}, nil)
+	if err == nil || !strings.Contains(err.Error(), "workload web01 is defined more than once") {
+		t.Errorf("plan error = %v, want the duplicate name", err)
+	}
+}
@@ -0,0 +1,284 @@
+package psm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestMigrationSinceIsCurrent(t *testing.T) {
+	startedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	since := migrationSince{startedAt: startedAt, previous: "2024-04-30T08:00:00Z"}
+
+	tests := []struct {
+		name   string
+		status *WorkloadMigrationStatus
+		want   bool
+	}{
+		{"no status", nil, false},
+		{"no start time", &WorkloadMigrationStatus{Stage: "migration-start"}, false},
+		{"earlier migration", &WorkloadMigrationStatus{StartedAt: "2024-04-30T08:00:00Z"}, false},
+		{"started long before", &WorkloadMigrationStatus{StartedAt: "2024-05-01T11:50:00Z"}, false},
+		{"PSM clock slightly behind", &WorkloadMigrationStatus{StartedAt: "2024-05-01T11:59:30Z"}, true},
+		{"started after", &WorkloadMigrationStatus{StartedAt: "2024-05-01T12:00:02Z"}, true},
+		{"unparsable start time", &WorkloadMigrationStatus{StartedAt: "just now"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := since.isCurrent(tt.status); got != tt.want {
+				t.Errorf("isCurrent() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlattenWorkloadMigrationStatus(t *testing.T) {
+	if got := flattenWorkloadMigrationStatus(nil); got != nil {
+		t.Errorf("flattenWorkloadMigrationStatus(nil) = %v, want nil", got)
+	}
+
+	got := flattenWorkloadMigrationStatus(&WorkloadMigrationStatus{Stage: "migration-done", Status: "done", StartedAt: "a", CompletedAt: "b"})
+	want := []interface{}{map[string]interface{}{"stage": "migration-done", "status": "done", "started_at": "a", "completed_at": "b"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("flattenWorkloadMigrationStatus() = %v, want %v", got, want)
+	}
+}
+
+// migrationServer is a fake PSM for one workload, which moves to the migration status given for each action once
+// that action is posted.
+type migrationServer struct {
+	mu       sync.Mutex
+	status   *WorkloadMigrationStatus
+	onAction map[string]WorkloadMigrationStatus
+	actions  []string
+}
+
+func (s *migrationServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.Method == http.MethodPost {
+		action := path.Base(r.URL.Path)
+		s.actions = append(s.actions, action)
+		if status, ok := s.onAction[action]; ok {
+			status.StartedAt = time.Now().UTC().Format(time.RFC3339)
+			s.status = &status
+		}
+		return
+	}
+
+	workload := &Workload{}
+	workload.Meta.Name = "vm1"
+	workload.Status.MigrationStatus = s.status
+	json.NewEncoder(w).Encode(workload)
+}
+
+func TestMigrateWorkload(t *testing.T) {
+	defer func(interval time.Duration) { workloadMigrationPollInterval = interval }(workloadMigrationPollInterval)
+	workloadMigrationPollInterval = 10 * time.Millisecond
+
+	// Left over from an earlier migration, which must not be taken for the outcome of this one
+	leftover := &WorkloadMigrationStatus{Stage: "migration-done", Status: "done", StartedAt: "2020-01-01T00:00:00Z"}
+
+	tests := []struct {
+		name        string
+		onAction    map[string]WorkloadMigrationStatus
+		wantActions []string
+		wantErr     string
+	}{
+		{
+			name: "started and finished",
+			onAction: map[string]WorkloadMigrationStatus{
+				"StartMigration":  {Stage: "migration-start", Status: "done"},
+				"FinishMigration": {Stage: "migration-done", Status: "done"},
+			},
+			wantActions: []string{"StartMigration", "FinishMigration"},
+		},
+		{
+			name: "done without finishing",
+			onAction: map[string]WorkloadMigrationStatus{
+				"StartMigration": {Stage: "migration-done", Status: "done"},
+			},
+			wantActions: []string{"StartMigration"},
+		},
+		{
+			name: "start failed",
+			onAction: map[string]WorkloadMigrationStatus{
+				"StartMigration": {Stage: "migration-start", Status: "failed"},
+			},
+			wantActions: []string{"StartMigration", "AbortMigration"},
+			wantErr:     `PSM reported stage "migration-start" status "failed"`,
+		},
+		{
+			name: "finish timed out",
+			onAction: map[string]WorkloadMigrationStatus{
+				"StartMigration":  {Stage: "migration-start", Status: "done"},
+				"FinishMigration": {Stage: "migration-done", Status: "timed-out"},
+			},
+			wantActions: []string{"StartMigration", "FinishMigration", "AbortMigration"},
+			wantErr:     `PSM reported stage "migration-done" status "timed-out"`,
+		},
+		{
+			name:        "no report on the new migration",
+			wantActions: []string{"StartMigration", "AbortMigration"},
+			wantErr:     "timeout waiting for stage migration-start or migration-done",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &migrationServer{status: leftover, onAction: tt.onAction}
+			server := httptest.NewServer(fake)
+			defer server.Close()
+			config := &Config{Server: server.URL}
+
+			d := schema.TestResourceDataRaw(t, resourceWorkload().Schema, map[string]interface{}{
+				"name":              "vm1",
+				"host_name":         "esx2",
+				"migration_timeout": "200ms",
+			})
+			workload := &Workload{}
+			workload.Meta.Name = "vm1"
+			workload.Spec.HostName = "esx2"
+
+			diags := migrateWorkload(context.Background(), d, config, workload)
+
+			if tt.wantErr == "" && diags.HasError() {
+				t.Errorf("migrateWorkload() error = %v", diags)
+			}
+			if tt.wantErr != "" && (!diags.HasError() || !strings.Contains(diags[0].Summary, tt.wantErr)) {
+				t.Errorf("migrateWorkload() error = %v, want %q", diags, tt.wantErr)
+			}
+			if !reflect.DeepEqual(fake.actions, tt.wantActions) {
+				t.Errorf("actions = %q, want %q", fake.actions, tt.wantActions)
+			}
+		})
+	}
+}
+
+func TestNormalizeMACAddress(t *testing.T) {
+	tests := []struct {
+		mac       string
+		want      string
+		wantValid bool
+	}{
+		{"0011.2233.4455", "0011.2233.4455", true},
+		{"00:11:22:33:44:55", "0011.2233.4455", true},
+		{"00-11-22-33-44-55", "0011.2233.4455", true},
+		{"001122334455", "0011.2233.4455", true},
+		{"AA:BB:CC:DD:EE:FF", "aabb.ccdd.eeff", true},
+		{"AABB.CCDD.EEFF", "aabb.ccdd.eeff", true},
+		{"00:11:22:33:44", "00:11:22:33:44", false},
+		{"00112233445566", "00112233445566", false},
+		{"00:00:00:00:fe:80:00:00:00:00:00:00:02:00:5e:10:00:00:00:01", "00:00:00:00:fe:80:00:00:00:00:00:00:02:00:5e:10:00:00:00:01", false},
+		{"not-a-mac", "not-a-mac", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		if got := normalizeMACAddress(tt.mac); got != tt.want {
+			t.Errorf("normalizeMACAddress(%q) = %q, want %q", tt.mac, got, tt.want)
+		}
+		if _, errs := validateMACAddress(tt.mac, "mac_address"); (len(errs) == 0) != tt.wantValid {
+			t.Errorf("validateMACAddress(%q) errors = %v, want valid %v", tt.mac, errs, tt.wantValid)
+		}
+	}
+}
+
+func TestSuppressEquivalentMACAddress(t *testing.T) {
+	tests := []struct {
+		old, new string
+		want     bool
+	}{
+		{"0011.2233.4455", "00:11:22:33:44:55", true},
+		{"aabb.ccdd.eeff", "AA-BB-CC-DD-EE-FF", true},
+		{"0011.2233.4455", "0011.2233.4456", false},
+		{"", "0011.2233.4455", false},
+	}
+
+	for _, tt := range tests {
+		if got := suppressEquivalentMACAddress("interface.0.mac_address", tt.old, tt.new, nil); got != tt.want {
+			t.Errorf("suppressEquivalentMACAddress(%q, %q) = %v, want %v", tt.old, tt.new, got, tt.want)
+		}
+	}
+}
+
+func TestCheckUniqueMACAddresses(t *testing.T) {
+	iface := func(mac string) interface{} { return map[string]interface{}{"mac_address": mac} }
+	known := func(int) bool { return true }
+
+	tests := []struct {
+		name       string
+		interfaces []interface{}
+		known      func(int) bool
+		wantErr    string
+	}{
+		{name: "unique", interfaces: []interface{}{iface("0011.2233.4455"), iface("0011.2233.4456")}, known: known},
+		{name: "no MAC", interfaces: []interface{}{iface(""), iface("")}, known: known},
+		{
+			name:       "same MAC in another notation",
+			interfaces: []interface{}{iface("0011.2233.4455"), iface("0011.2233.4456"), iface("00:11:22:33:44:55")},
+			known:      known,
+			wantErr:    "interface.2.mac_address: MAC address 0011.2233.4455 is already used by interface.0",
+		},
+		{
+			name:       "unknown MAC",
+			interfaces: []interface{}{iface("0011.2233.4455"), iface("0011.2233.4455")},
+			known:      func(i int) bool { return i == 0 },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkUniqueMACAddresses(tt.interfaces, tt.known)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("checkUniqueMACAddresses() error = %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("checkUniqueMACAddresses() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestResourceWorkloadValidateInterfaces(t *testing.T) {
+	config := func(iface map[string]interface{}) map[string]interface{} {
+		iface["mac_address"] = "0011.2233.4455"
+		return map[string]interface{}{"name": "vm1", "host_name": "esx1", "interface": []interface{}{iface}}
+	}
+
+	tests := []struct {
+		name    string
+		iface   map[string]interface{}
+		wantErr bool
+	}{
+		{name: "valid", iface: map[string]interface{}{"external_vlan": 100, "micro_seg_vlan": 4095, "vni": 16777215, "ip_addresses": []interface{}{"10.0.0.10", "2001:db8::10"}}},
+		{name: "external_vlan out of range", iface: map[string]interface{}{"external_vlan": 4096}, wantErr: true},
+		{name: "micro_seg_vlan zero", iface: map[string]interface{}{"micro_seg_vlan": 0}, wantErr: true},
+		{name: "vni out of range", iface: map[string]interface{}{"vni": 16777216}, wantErr: true},
+		{name: "CIDR instead of address", iface: map[string]interface{}{"ip_addresses": []interface{}{"10.0.0.0/24"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := resourceWorkload().Validate(terraform.NewResourceConfigRaw(config(tt.iface)))
+			if diags.HasError() != tt.wantErr {
+				t.Errorf("Validate() = %v, want error %v", diags, tt.wantErr)
+			}
+		})
+	}
+}
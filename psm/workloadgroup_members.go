@@ -0,0 +1,146 @@
+package psm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceWorkloadGroupMembers() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceWorkloadGroupMembersRead,
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Name of the workload group to evaluate",
+			},
+			"workloads": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Sorted names of the workloads matched by the group's selectors",
+			},
+			"ip_addresses": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Sorted, de-duplicated addresses of the matched workloads and of the group's IP collections",
+			},
+			"member": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"host_name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"ip_addresses": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"labels": {
+							Type:     schema.TypeMap,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceWorkloadGroupMembersRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	config := m.(*Config)
+	name := d.Get("name").(string)
+
+	group, err := getWorkloadGroup(ctx, config, name)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	workloads, err := listWorkloads(ctx, config)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	sort.Slice(workloads, func(i, j int) bool { return workloads[i].Meta.Name < workloads[j].Meta.Name })
+
+	names := []string{}
+	members := make([]interface{}, 0)
+	var addresses []string
+	for _, workload := range workloads {
+		if !matchesWorkloadSelectors(group.Spec.WorkloadSelector, workload.Meta.Labels) {
+			continue
+		}
+		var workloadAddresses []string
+		for _, iface := range workload.Spec.Interfaces {
+			workloadAddresses = append(workloadAddresses, iface.IPAddresses...)
+		}
+		workloadAddresses = normalizeAddresses(workloadAddresses)
+		addresses = append(addresses, workloadAddresses...)
+
+		names = append(names, workload.Meta.Name)
+		members = append(members, map[string]interface{}{
+			"name":         workload.Meta.Name,
+			"host_name":    workload.Spec.HostName,
+			"ip_addresses": workloadAddresses,
+			"labels":       workload.Meta.Labels,
+		})
+	}
+
+	// Addresses of IP collections attached to the group are members as well, including nested collections
+	for _, collectionName := range group.Spec.IpCollections {
+		collection, err := getIPCollection(ctx, config, collectionName)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if collection == nil {
+			return diag.Errorf("ip_collection %s of workload group %s not found", collectionName, name)
+		}
+		collectionAddresses, _, err := expandNestedIPCollection(ctx, config, collection)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		addresses = append(addresses, collectionAddresses...)
+	}
+
+	if err := d.Set("workloads", names); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("ip_addresses", normalizeAddresses(addresses)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("member", members); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(name)
+
+	return nil
+}
+
+// getWorkloadGroup returns the workload group with the given name.
+func getWorkloadGroup(ctx context.Context, config *Config, name string) (*WorkloadGroup, error) {
+	url := fmt.Sprintf("%s/configs/workload/v1/tenant/default/workloadgroups/%s", config.Server, name)
+	body, err := doNetworkRequest(ctx, config, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workload group %s: %w", name, err)
+	}
+
+	group := &WorkloadGroup{}
+	if err := json.Unmarshal(body, group); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
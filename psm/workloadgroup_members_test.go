@@ -0,0 +1,60 @@
+package psm
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestDataSourceWorkloadGroupMembersRead(t *testing.T) {
+	config := newTestServer(t, map[string]string{
+		"/configs/workload/v1/tenant/default/workloadgroups/web": `{
+			"meta": {"name": "web"},
+			"spec": {
+				"workload-selector": [{"requirements": [{"key": "tier", "operator": "equals", "values": ["web"]}]}],
+				"ip-collections": ["ipc-lb"]
+			}
+		}`,
+		"/configs/workload/v1/tenant/default/workloads": `{"items": [
+			{"meta": {"name": "web2", "labels": {"tier": "web"}}, "spec": {"host-name": "esx2", "interfaces": [{"ip-addresses": ["10.0.0.12/32"]}]}},
+			{"meta": {"name": "db1", "labels": {"tier": "db"}}, "spec": {"host-name": "esx1", "interfaces": [{"ip-addresses": ["10.0.1.10"]}]}},
+			{"meta": {"name": "web1", "labels": {"tier": "web"}}, "spec": {"host-name": "esx1", "interfaces": [{"ip-addresses": ["10.0.0.11", "10.0.0.12"]}]}}
+		]}`,
+		"/configs/network/v1/tenant/default/ipcollections/ipc-lb":  `{"meta": {"name": "ipc-lb"}, "spec": {"addresses": ["10.0.0.1"], "ipcollections": ["ipc-vip"]}}`,
+		"/configs/network/v1/tenant/default/ipcollections/ipc-vip": `{"meta": {"name": "ipc-vip"}, "spec": {"addresses": ["192.0.2.10", "10.0.0.11"]}}`,
+	})
+
+	d := schema.TestResourceDataRaw(t, dataSourceWorkloadGroupMembers().Schema, map[string]interface{}{"name": "web"})
+	if diags := dataSourceWorkloadGroupMembersRead(context.Background(), d, config); diags.HasError() {
+		t.Fatalf("read error = %v", diags)
+	}
+
+	if got, want := expandStringList(d.Get("workloads").([]interface{})), []string{"web1", "web2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("workloads = %q, want %q", got, want)
+	}
+	want := []string{"10.0.0.1", "10.0.0.11", "10.0.0.12", "192.0.2.10"}
+	if got := expandStringList(d.Get("ip_addresses").([]interface{})); !reflect.DeepEqual(got, want) {
+		t.Errorf("ip_addresses = %q, want %q", got, want)
+	}
+	if got := d.Get("member.0.host_name"); got != "esx1" {
+		t.Errorf("member.0.host_name = %v, want esx1", got)
+	}
+	if got, want := expandStringList(d.Get("member.1.ip_addresses").([]interface{})), []string{"10.0.0.12"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("member.1.ip_addresses = %q, want %q", got, want)
+	}
+}
+
+func TestDataSourceWorkloadGroupMembersReadMissingCollection(t *testing.T) {
+	config := newTestServer(t, map[string]string{
+		"/configs/workload/v1/tenant/default/workloadgroups/web": `{"meta": {"name": "web"}, "spec": {"ip-collections": ["gone"]}}`,
+		"/configs/workload/v1/tenant/default/workloads":          `{"items": []}`,
+	})
+
+	d := schema.TestResourceDataRaw(t, dataSourceWorkloadGroupMembers().Schema, map[string]interface{}{"name": "web"})
+	diags := dataSourceWorkloadGroupMembersRead(context.Background(), d, config)
+	if !diags.HasError() || diags[0].Summary != "ip_collection gone of workload group web not found" {
+		t.Errorf("read error = %v, want the missing ip_collection", diags)
+	}
+}